@@ -0,0 +1,351 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/semver"
+)
+
+type BranchingModelType string
+
+const (
+	// BranchingModelGitFlow creates a release branch from the dev branch, merges it into the main branch, tags
+	// main and then merges main back into dev.
+	BranchingModelGitFlow BranchingModelType = "git-flow"
+
+	// BranchingModelTrunk commits the version bump and tags it directly on the main branch. This covers both
+	// trunk-based development and GitHub flow.
+	BranchingModelTrunk BranchingModelType = "trunk"
+
+	// BranchingModelReleaseBranch creates a release branch for each minor version (e.g. release/1.2) from the main
+	// branch, tags it on the release branch and merges it back into main. The release branch is kept around so that
+	// patch releases of that minor version can be made from it.
+	BranchingModelReleaseBranch BranchingModelType = "release-branch"
+)
+
+// BranchingModel decides which branches a bump happens on and how the version bump commit gets to where it's
+// tagged and published.
+type BranchingModel interface {
+	Name() string
+
+	// StartBranch is the branch that bumps need to be started from.
+	StartBranch() string
+
+	// ReleaseBranch returns the name of the branch to make the version bump commit on.
+	ReleaseBranch(newVersion string) string
+
+	// DevelopmentBranch is the branch where development for the next release happens.
+	DevelopmentBranch() string
+
+	// PrepareSteps are run before the package and changelog are updated.
+	PrepareSteps(c *bumpContext) []BumpStep
+
+	// PublishSteps are run after the version bump is committed, and are responsible for tagging and pushing.
+	PublishSteps(c *bumpContext) []BumpStep
+}
+
+func NewBranchingModel(conf *Config) (BranchingModel, error) {
+	switch conf.BranchingModel {
+	case BranchingModelGitFlow:
+		return &GitFlowModel{
+			mainBranch:          conf.MainBranch,
+			devBranch:           conf.DevBranch,
+			releaseBranchPrefix: conf.ReleaseBranchPrefix,
+			mergeRequest:        conf.MergeRequest,
+		}, nil
+	case BranchingModelTrunk:
+		return &TrunkModel{
+			mainBranch:          conf.MainBranch,
+			releaseBranchPrefix: conf.ReleaseBranchPrefix,
+			mergeRequest:        conf.MergeRequest,
+		}, nil
+	case BranchingModelReleaseBranch:
+		if conf.MergeRequest {
+			return nil, errors.New("merge requests aren't supported with the release-branch branching model")
+		}
+
+		return &ReleaseBranchModel{
+			mainBranch:          conf.MainBranch,
+			releaseBranchPrefix: conf.ReleaseBranchPrefix,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported branching model: %s", conf.BranchingModel)
+	}
+}
+
+type GitFlowModel struct {
+	mainBranch          string
+	devBranch           string
+	releaseBranchPrefix string
+	mergeRequest        bool
+}
+
+func (m *GitFlowModel) Name() string {
+	return string(BranchingModelGitFlow)
+}
+
+func (m *GitFlowModel) StartBranch() string {
+	return m.devBranch
+}
+
+func (m *GitFlowModel) ReleaseBranch(newVersion string) string {
+	return m.releaseBranchPrefix + newVersion
+}
+
+func (m *GitFlowModel) DevelopmentBranch() string {
+	return m.devBranch
+}
+
+func (m *GitFlowModel) PrepareSteps(c *bumpContext) []BumpStep {
+	return []BumpStep{
+		{
+			Name: "create release branch",
+			Run:  c.createReleaseBranch,
+			Undo: c.undoCreateReleaseBranch,
+		},
+	}
+}
+
+func (m *GitFlowModel) PublishSteps(c *bumpContext) []BumpStep {
+	if m.mergeRequest {
+		return mergeRequestSteps(c, m.mainBranch, m.devBranch)
+	}
+
+	return []BumpStep{
+		{
+			Name: fmt.Sprintf("merge release branch into %s", m.mainBranch),
+			Run:  func() error { return c.mergeReleaseBranch(m.mainBranch) },
+			Undo: func() error { return c.undoMergeReleaseBranch(m.mainBranch) },
+		},
+		{
+			Name: "delete release branch",
+			Run:  c.deleteReleaseBranch,
+			Undo: c.undoDeleteReleaseBranch,
+		},
+		{
+			Name: "tag release",
+			Run:  c.tagRelease,
+			Undo: c.undoTagRelease,
+		},
+		{
+			Name:      fmt.Sprintf("push %s", m.mainBranch),
+			Run:       c.git.Push,
+			Published: true,
+		},
+		{
+			Name:      "push tags",
+			Run:       c.git.PushTags,
+			Published: true,
+		},
+		{
+			Name: fmt.Sprintf("merge %s into %s", m.mainBranch, m.devBranch),
+			Run:  func() error { return c.mergeBranch(m.mainBranch, m.devBranch) },
+		},
+		{
+			Name:      fmt.Sprintf("push %s", m.devBranch),
+			Run:       c.git.Push,
+			Published: true,
+		},
+	}
+}
+
+type TrunkModel struct {
+	mainBranch          string
+	releaseBranchPrefix string
+
+	// With merge requests, the version bump goes via a release branch as main can't be pushed to directly.
+	mergeRequest bool
+}
+
+func (m *TrunkModel) Name() string {
+	return string(BranchingModelTrunk)
+}
+
+func (m *TrunkModel) StartBranch() string {
+	return m.mainBranch
+}
+
+func (m *TrunkModel) ReleaseBranch(newVersion string) string {
+	if m.mergeRequest {
+		return m.releaseBranchPrefix + newVersion
+	}
+
+	return m.mainBranch
+}
+
+func (m *TrunkModel) DevelopmentBranch() string {
+	return m.mainBranch
+}
+
+func (m *TrunkModel) PrepareSteps(c *bumpContext) []BumpStep {
+	if m.mergeRequest {
+		return []BumpStep{
+			{
+				Name: "create release branch",
+				Run:  c.createReleaseBranch,
+				Undo: c.undoCreateReleaseBranch,
+			},
+		}
+	}
+
+	return nil
+}
+
+func (m *TrunkModel) PublishSteps(c *bumpContext) []BumpStep {
+	if m.mergeRequest {
+		return mergeRequestSteps(c, m.mainBranch, "")
+	}
+
+	return []BumpStep{
+		{
+			Name: "tag release",
+			Run:  c.tagRelease,
+			Undo: c.undoTagRelease,
+		},
+		{
+			Name:      fmt.Sprintf("push %s", m.mainBranch),
+			Run:       c.git.Push,
+			Published: true,
+		},
+		{
+			Name:      "push tags",
+			Run:       c.git.PushTags,
+			Published: true,
+		},
+	}
+}
+
+type ReleaseBranchModel struct {
+	mainBranch          string
+	releaseBranchPrefix string
+
+	// For patch releases made from an existing release branch, the branch the bump is made on. Empty when
+	// starting a new release line from the main branch.
+	maintenanceBranch string
+}
+
+// newMaintenanceModel is used to release a new version of an existing release line from its release branch.
+func newMaintenanceModel(conf *Config, releaseBranch string) *ReleaseBranchModel {
+	return &ReleaseBranchModel{
+		mainBranch:          conf.MainBranch,
+		releaseBranchPrefix: conf.ReleaseBranchPrefix,
+		maintenanceBranch:   releaseBranch,
+	}
+}
+
+func (m *ReleaseBranchModel) Name() string {
+	return string(BranchingModelReleaseBranch)
+}
+
+func (m *ReleaseBranchModel) StartBranch() string {
+	if m.maintenanceBranch != "" {
+		return m.maintenanceBranch
+	}
+
+	return m.mainBranch
+}
+
+// ReleaseBranch returns the branch for the release line of the new version, e.g. release/1.2 for v1.2.3.
+func (m *ReleaseBranchModel) ReleaseBranch(newVersion string) string {
+	return m.releaseBranchPrefix + releaseLine(newVersion)
+}
+
+func (m *ReleaseBranchModel) DevelopmentBranch() string {
+	return m.mainBranch
+}
+
+func (m *ReleaseBranchModel) PrepareSteps(c *bumpContext) []BumpStep {
+	if m.maintenanceBranch != "" {
+		return nil
+	}
+
+	return []BumpStep{
+		{
+			Name: "create release branch",
+			Run:  c.createReleaseBranch,
+			Undo: c.undoCreateReleaseBranch,
+		},
+	}
+}
+
+func (m *ReleaseBranchModel) PublishSteps(c *bumpContext) []BumpStep {
+	steps := []BumpStep{
+		{
+			Name: "tag release",
+			Run:  c.tagRelease,
+			Undo: c.undoTagRelease,
+		},
+		{
+			Name:      "push release branch",
+			Run:       func() error { return c.git.PushBranch(c.ReleaseBranch) },
+			Published: true,
+		},
+		{
+			Name:      "push tags",
+			Run:       c.git.PushTags,
+			Published: true,
+		},
+	}
+
+	// Main has moved on by the time there are any maintenance releases, so only the first release of each line is
+	// merged back. This keeps the package version on main in step with the latest tag reachable from it.
+	if m.maintenanceBranch != "" {
+		return steps
+	}
+
+	return append(steps, []BumpStep{
+		{
+			Name: fmt.Sprintf("merge release branch into %s", m.mainBranch),
+			Run:  func() error { return c.mergeBranch(c.ReleaseBranch, m.mainBranch) },
+		},
+		{
+			Name:      fmt.Sprintf("push %s", m.mainBranch),
+			Run:       func() error { return c.git.PushBranch(m.mainBranch) },
+			Published: true,
+		},
+	}...)
+}
+
+// checkReleaseLine makes sure that the new version can be released from the current branch with the release-branch
+// model. New release lines are started from main, and later releases of a line are made from its release branch.
+func checkReleaseLine(git *GitWrapper, c *bumpContext, currentBranch string) error {
+	if c.Maintenance {
+		if c.ReleaseBranch != currentBranch {
+			return fmt.Errorf(
+				"%s isn't part of the release line on %s - bump from the main branch to start a new release line",
+				c.NewTag(),
+				currentBranch,
+			)
+		}
+
+		return nil
+	}
+
+	if git.BranchExists(c.ReleaseBranch) {
+		return fmt.Errorf(
+			"release branch %s already exists - make releases of that line from the release branch",
+			c.ReleaseBranch,
+		)
+	}
+
+	return nil
+}
+
+// releaseLine returns the major and minor version of the tag, keeping any package prefix, e.g. api/1.2 for
+// api/v1.2.3.
+func releaseLine(tag string) string {
+	prefix, version, found := cutLast(tag, "/")
+	if !found {
+		prefix, version = "", tag
+	} else {
+		prefix += "/"
+	}
+
+	parsedVersion, err := semver.NewVersion(version)
+	if err != nil {
+		return tag
+	}
+
+	return fmt.Sprintf("%s%d.%d", prefix, parsedVersion.Major(), parsedVersion.Minor())
+}
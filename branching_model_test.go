@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestReleaseLine(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"v1.2.0", "1.2"},
+		{"v1.2.3", "1.2"},
+		{"v2.0.0-rc.1", "2.0"},
+		{"api/v1.4.0", "api/1.4"},
+		{"services/api/v0.3.1", "services/api/0.3"},
+	}
+
+	for _, tt := range tests {
+		if got := releaseLine(tt.tag); got != tt.want {
+			t.Errorf("releaseLine(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
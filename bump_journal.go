@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrBumpCancelled = errors.New("version bump cancelled")
+
+// BumpStep is a single step of a version bump.
+type BumpStep struct {
+	Name string
+
+	Run func() error
+
+	// Undo reverts the step. It's also called if the step itself fails, so it needs to cope with the step only
+	// having partially completed. Nil if there's nothing to undo.
+	Undo func() error
+
+	// Published steps change state outside the local repository (e.g. pushing or creating the release) and so
+	// can't be undone. Once a published step has been run, failures no longer trigger a rollback.
+	Published bool
+}
+
+// BumpState is everything about a bump which needs to be persisted in the journal file so that an interrupted
+// bump can be resumed.
+type BumpState struct {
+	ProjectName string `json:"project_name"`
+	LatestTag   string `json:"latest_tag"`
+	NewVersion  string `json:"new_version"`
+	Prerelease  bool   `json:"prerelease,omitempty"`
+
+	// In monorepo mode, the path of the package being bumped relative to the repository root, and the prefix of
+	// its tags, e.g. "packages/api/" for packages/api/v1.4.0.
+	PackagePath string `json:"package_path,omitempty"`
+	TagPrefix   string `json:"tag_prefix,omitempty"`
+
+	ReleaseBranch string `json:"release_branch"`
+	Hotfix        bool   `json:"hotfix,omitempty"`
+	Maintenance   bool   `json:"maintenance,omitempty"`
+	ReleaseNotes  string `json:"release_notes"`
+
+	// Commits recorded before steps which move branches, so that they can be restored on rollback.
+	PreCommit     string `json:"pre_commit,omitempty"`
+	MainCommit    string `json:"main_commit,omitempty"`
+	ReleaseCommit string `json:"release_commit,omitempty"`
+	TagCreated    bool   `json:"tag_created,omitempty"`
+
+	MergeRequest   bool   `json:"merge_request,omitempty"`
+	MergeRequestID int    `json:"merge_request_id,omitempty"`
+	MergeCommit    string `json:"merge_commit,omitempty"`
+
+	CompletedSteps []string `json:"completed_steps"`
+}
+
+// PreviousTag returns the name of the tag for the version being bumped from, including the package prefix in
+// monorepo mode.
+func (s *BumpState) PreviousTag() string {
+	return s.TagPrefix + s.LatestTag
+}
+
+// NewTag returns the name of the tag for the new version, including the package prefix in monorepo mode.
+func (s *BumpState) NewTag() string {
+	return s.TagPrefix + s.NewVersion
+}
+
+// BumpJournal runs a sequence of bump steps, keeping track of which have completed so that a failure part-way
+// through can either be rolled back or reported. The state is written to the journal file after each step so that
+// the bump can be resumed with `bumper resume` if it fails after changes have been published.
+type BumpJournal struct {
+	steps     []BumpStep
+	state     *BumpState
+	filePath  string
+	completed int
+}
+
+// NewBumpJournal creates a journal for the given steps. If the file path is empty, the journal isn't persisted.
+func NewBumpJournal(steps []BumpStep, state *BumpState, filePath string) (*BumpJournal, error) {
+	if len(state.CompletedSteps) > len(steps) {
+		return nil, fmt.Errorf("journal has more completed steps than expected")
+	}
+
+	for i, stepName := range state.CompletedSteps {
+		if steps[i].Name != stepName {
+			return nil, fmt.Errorf("journal step '%s' doesn't match expected step '%s'", stepName, steps[i].Name)
+		}
+	}
+
+	return &BumpJournal{
+		steps:     steps,
+		state:     state,
+		filePath:  filePath,
+		completed: len(state.CompletedSteps),
+	}, nil
+}
+
+// LoadBumpState reads the state of an interrupted bump from the journal file.
+func LoadBumpState(filePath string) (*BumpState, error) {
+	stateBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading bump journal: %w", err)
+	}
+
+	var state BumpState
+	if err := json.Unmarshal(stateBytes, &state); err != nil {
+		return nil, fmt.Errorf("error parsing bump journal: %w", err)
+	}
+
+	return &state, nil
+}
+
+func (j *BumpJournal) Run() error {
+	if j.completed > 0 && j.completed < len(j.steps) {
+		log.Info().Msgf("Resuming bump to %s from step '%s'", j.state.NewVersion, j.steps[j.completed].Name)
+	}
+
+	if err := j.save(); err != nil {
+		return err
+	}
+
+	for j.completed < len(j.steps) {
+		step := j.steps[j.completed]
+
+		log.Debug().Msgf("Running bump step: %s", step.Name)
+		if err := step.Run(); err != nil {
+			if errors.Is(err, ErrBumpPaused) {
+				return err
+			}
+
+			return j.fail(fmt.Errorf("error during '%s': %w", step.Name, err), j.completed, true)
+		}
+
+		j.completed++
+		j.state.CompletedSteps = append(j.state.CompletedSteps, step.Name)
+
+		// If the journal doesn't match what's actually been done, it can't be used to resume, so this is as bad
+		// as the step itself failing.
+		if err := j.save(); err != nil {
+			return j.fail(fmt.Errorf("error after '%s': %w", step.Name, err), j.completed-1, false)
+		}
+	}
+
+	return j.remove()
+}
+
+// fail handles a failure part-way through the bump, either rolling back everything up to and including the last
+// step which was started or, if changes have already been published, reporting how to recover.
+func (j *BumpJournal) fail(stepErr error, lastStarted int, journalSaved bool) error {
+	if j.isPublished() {
+		log.Error().Msg(j.recoveryReport(stepErr, journalSaved))
+		return stepErr
+	}
+
+	if !errors.Is(stepErr, ErrBumpCancelled) {
+		log.Error().Err(stepErr).Msg("Bump failed - rolling back changes")
+	}
+
+	if rollbackErr := j.rollback(lastStarted); rollbackErr != nil {
+		return errors.Join(stepErr, rollbackErr)
+	}
+
+	return stepErr
+}
+
+func (j *BumpJournal) save() error {
+	if j.filePath == "" {
+		return nil
+	}
+
+	stateBytes, err := json.MarshalIndent(j.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding bump journal: %w", err)
+	}
+
+	if err := os.WriteFile(j.filePath, stateBytes, 0600); err != nil {
+		return fmt.Errorf("error writing bump journal: %w", err)
+	}
+
+	return nil
+}
+
+func (j *BumpJournal) remove() error {
+	if j.filePath == "" {
+		return nil
+	}
+
+	if err := os.Remove(j.filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing bump journal: %w", err)
+	}
+
+	return nil
+}
+
+// isPublished returns true if any of the completed steps have made changes which can't be undone.
+func (j *BumpJournal) isPublished() bool {
+	for _, step := range j.steps[:j.completed] {
+		if step.Published {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rollback undoes the steps up to and including lastStarted, in reverse order. It carries on past any undo
+// failures so that as much as possible gets cleaned up.
+func (j *BumpJournal) rollback(lastStarted int) error {
+	var errs []error
+
+	for i := lastStarted; i >= 0; i-- {
+		step := j.steps[i]
+		if step.Undo == nil {
+			continue
+		}
+
+		log.Debug().Msgf("Undoing bump step: %s", step.Name)
+		if err := step.Undo(); err != nil {
+			errs = append(errs, fmt.Errorf("error undoing '%s': %w", step.Name, err))
+		}
+	}
+
+	// There's nothing left to resume, even if the rollback wasn't entirely successful.
+	if err := j.remove(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("rollback incomplete - repository may need manual cleanup: %w", errors.Join(errs...))
+	}
+
+	log.Debug().Msg("Rolled back all changes")
+	return nil
+}
+
+func (j *BumpJournal) recoveryReport(stepErr error, journalSaved bool) string {
+	var report strings.Builder
+
+	report.WriteString("Bump failed after changes were published, so it can't be rolled back automatically.\n")
+	report.WriteString(fmt.Sprintf("Failure: %v\n", stepErr))
+
+	report.WriteString("Completed steps:\n")
+	for _, step := range j.steps[:j.completed] {
+		report.WriteString(fmt.Sprintf("  ✓ %s\n", step.Name))
+	}
+
+	report.WriteString("Remaining steps:\n")
+	for _, step := range j.steps[j.completed:] {
+		report.WriteString(fmt.Sprintf("  ✗ %s\n", step.Name))
+	}
+
+	if j.filePath != "" && journalSaved {
+		report.WriteString("Once the problem is fixed, run `bumper resume` to complete the remaining steps.\n")
+	} else if j.filePath != "" {
+		report.WriteString(fmt.Sprintf(
+			"The bump journal %s couldn't be updated, so `bumper resume` would repeat completed steps - "+
+				"complete the remaining steps manually.\n",
+			j.filePath,
+		))
+	} else {
+		report.WriteString("The remaining steps need to be completed manually.\n")
+	}
+
+	return report.String()
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// journalTestSteps builds steps which make real changes to the test repository, recording the order in which the
+// steps are run and undone.
+type journalTestSteps struct {
+	t      *testing.T
+	calls  []string
+	failAt string
+}
+
+func (s *journalTestSteps) step(name string, run func() error, undo func() error) BumpStep {
+	return BumpStep{
+		Name: name,
+		Run: func() error {
+			s.calls = append(s.calls, name)
+			if name == s.failAt {
+				return errors.New("something went wrong")
+			}
+
+			return run()
+		},
+		Undo: func() error {
+			s.calls = append(s.calls, "undo "+name)
+			if undo == nil {
+				return nil
+			}
+
+			return undo()
+		},
+	}
+}
+
+func (s *journalTestSteps) commitStep(preCommit string) BumpStep {
+	return s.step(
+		"commit",
+		func() error {
+			writeTestFile(s.t, "VERSION", "1.1.0\n")
+			runGit(s.t, "add", "VERSION")
+			runGit(s.t, "commit", "-q", "-m", "Bump version to v1.1.0")
+			return nil
+		},
+		func() error {
+			runGit(s.t, "reset", "-q", "--hard", preCommit)
+			return nil
+		},
+	)
+}
+
+func (s *journalTestSteps) tagStep() BumpStep {
+	return s.step(
+		"tag",
+		func() error {
+			runGit(s.t, "tag", "v1.1.0")
+			return nil
+		},
+		func() error {
+			// The tag might not have been created if this step failed.
+			_ = exec.Command("git", "tag", "-d", "v1.1.0").Run()
+			return nil
+		},
+	)
+}
+
+// captureLog sends log output to a buffer for the rest of the test.
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	logger := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = logger })
+
+	return &buf
+}
+
+func TestBumpJournalRun(t *testing.T) {
+	newTestRepo(t)
+	journalPath := path.Join(t.TempDir(), "journal.json")
+	s := &journalTestSteps{t: t}
+
+	steps := []BumpStep{
+		s.commitStep(runGit(t, "rev-parse", "HEAD")),
+		s.step("check journal", func() error {
+			state, err := LoadBumpState(journalPath)
+			if err != nil {
+				return err
+			}
+
+			if !slices.Equal(state.CompletedSteps, []string{"commit"}) {
+				t.Errorf("journal completed steps = %v, want [commit]", state.CompletedSteps)
+			}
+
+			return nil
+		}, nil),
+		s.tagStep(),
+	}
+
+	journal, err := NewBumpJournal(steps, &BumpState{NewVersion: "v1.1.0"}, journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if want := []string{"commit", "check journal", "tag"}; !slices.Equal(s.calls, want) {
+		t.Errorf("calls = %v, want %v", s.calls, want)
+	}
+
+	if runGit(t, "tag", "-l", "v1.1.0") != "v1.1.0" {
+		t.Error("tag v1.1.0 not created")
+	}
+
+	if fileExists(journalPath) {
+		t.Error("journal not removed after a successful bump")
+	}
+}
+
+func TestBumpJournalRollback(t *testing.T) {
+	newTestRepo(t)
+	captureLog(t)
+	journalPath := path.Join(t.TempDir(), "journal.json")
+	preCommit := runGit(t, "rev-parse", "HEAD")
+	s := &journalTestSteps{t: t, failAt: "release"}
+
+	steps := []BumpStep{
+		s.commitStep(preCommit),
+		s.tagStep(),
+		s.step("release", nil, nil),
+		s.step("never run", nil, nil),
+	}
+
+	journal, err := NewBumpJournal(steps, &BumpState{NewVersion: "v1.1.0"}, journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = journal.Run()
+	if err == nil || !strings.Contains(err.Error(), "error during 'release'") {
+		t.Fatalf("Run() error = %v, want error during 'release'", err)
+	}
+
+	want := []string{"commit", "tag", "release", "undo release", "undo tag", "undo commit"}
+	if !slices.Equal(s.calls, want) {
+		t.Errorf("calls = %v, want %v", s.calls, want)
+	}
+
+	if head := runGit(t, "rev-parse", "HEAD"); head != preCommit {
+		t.Errorf("HEAD = %s, want %s", head, preCommit)
+	}
+
+	if tags := runGit(t, "tag", "-l", "v1.1.0"); tags != "" {
+		t.Errorf("tag v1.1.0 still exists after rollback")
+	}
+
+	if fileExists(journalPath) {
+		t.Error("journal not removed after rollback")
+	}
+}
+
+func TestBumpJournalPublishedStepsAreNotRolledBack(t *testing.T) {
+	newTestRepo(t)
+	logOutput := captureLog(t)
+	journalPath := path.Join(t.TempDir(), "journal.json")
+	s := &journalTestSteps{t: t, failAt: "release"}
+
+	pushStep := s.step("push", func() error { return nil }, nil)
+	pushStep.Published = true
+
+	steps := []BumpStep{
+		s.commitStep(runGit(t, "rev-parse", "HEAD")),
+		pushStep,
+		s.step("release", nil, nil),
+	}
+
+	journal, err := NewBumpJournal(steps, &BumpState{NewVersion: "v1.1.0"}, journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Run(); err == nil {
+		t.Fatal("Run() succeeded, want error")
+	}
+
+	if want := []string{"commit", "push", "release"}; !slices.Equal(s.calls, want) {
+		t.Errorf("calls = %v, want %v", s.calls, want)
+	}
+
+	state, err := LoadBumpState(journalPath)
+	if err != nil {
+		t.Fatalf("journal not kept for resuming: %v", err)
+	}
+
+	if want := []string{"commit", "push"}; !slices.Equal(state.CompletedSteps, want) {
+		t.Errorf("journal completed steps = %v, want %v", state.CompletedSteps, want)
+	}
+
+	for _, wantLine := range []string{"✓ commit", "✓ push", "✗ release", "bumper resume"} {
+		if !strings.Contains(logOutput.String(), wantLine) {
+			t.Errorf("recovery report %s doesn't contain %q", logOutput, wantLine)
+		}
+	}
+}
+
+func TestBumpJournalSaveFailure(t *testing.T) {
+	tests := []struct {
+		name      string
+		published bool
+		wantCalls []string
+	}{
+		{"unpublished", false, []string{"commit", "break journal", "undo break journal", "undo commit"}},
+		{"published", true, []string{"commit", "break journal"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestRepo(t)
+			logOutput := captureLog(t)
+			journalDir := path.Join(t.TempDir(), "journal")
+			if err := os.Mkdir(journalDir, 0700); err != nil {
+				t.Fatal(err)
+			}
+			journalPath := path.Join(journalDir, "journal.json")
+			s := &journalTestSteps{t: t}
+
+			// Removing the directory means the journal can't be saved once the step has completed.
+			breakJournal := s.step("break journal", func() error { return os.RemoveAll(journalDir) }, nil)
+			breakJournal.Published = tt.published
+
+			steps := []BumpStep{
+				s.commitStep(runGit(t, "rev-parse", "HEAD")),
+				breakJournal,
+				s.tagStep(),
+			}
+
+			journal, err := NewBumpJournal(steps, &BumpState{NewVersion: "v1.1.0"}, journalPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = journal.Run()
+			if err == nil || !strings.Contains(err.Error(), "error writing bump journal") {
+				t.Fatalf("Run() error = %v, want error writing bump journal", err)
+			}
+
+			if !slices.Equal(s.calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", s.calls, tt.wantCalls)
+			}
+
+			if tt.published && !strings.Contains(logOutput.String(), "couldn't be updated") {
+				t.Errorf("recovery report %s doesn't say the journal couldn't be updated", logOutput)
+			}
+		})
+	}
+}
+
+func TestBumpJournalPauseAndResume(t *testing.T) {
+	newTestRepo(t)
+	journalPath := path.Join(t.TempDir(), "journal.json")
+	s := &journalTestSteps{t: t}
+
+	paused := true
+	steps := func() []BumpStep {
+		return []BumpStep{
+			s.commitStep(runGit(t, "rev-parse", "HEAD")),
+			s.step("wait", func() error {
+				if paused {
+					return ErrBumpPaused
+				}
+
+				return nil
+			}, nil),
+			s.tagStep(),
+		}
+	}
+
+	journal, err := NewBumpJournal(steps(), &BumpState{NewVersion: "v1.1.0"}, journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Run(); !errors.Is(err, ErrBumpPaused) {
+		t.Fatalf("Run() error = %v, want ErrBumpPaused", err)
+	}
+
+	state, err := LoadBumpState(journalPath)
+	if err != nil {
+		t.Fatalf("journal not kept when paused: %v", err)
+	}
+
+	if !slices.Equal(state.CompletedSteps, []string{"commit"}) {
+		t.Errorf("journal completed steps = %v, want [commit]", state.CompletedSteps)
+	}
+
+	paused = false
+	s.calls = nil
+
+	journal, err = NewBumpJournal(steps(), state, journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Run(); err != nil {
+		t.Fatalf("resumed Run() error = %v", err)
+	}
+
+	if want := []string{"wait", "tag"}; !slices.Equal(s.calls, want) {
+		t.Errorf("resumed calls = %v, want %v", s.calls, want)
+	}
+
+	state.CompletedSteps = []string{"something else"}
+	if _, err := NewBumpJournal(steps(), state, journalPath); err == nil {
+		t.Error("NewBumpJournal() succeeded with mismatched steps, want error")
+	}
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/rs/zerolog/log"
+)
+
+// bumpContext holds everything needed to run the steps of a version bump.
+type bumpContext struct {
+	conf             *Config
+	git              *GitWrapper
+	packager         Packager
+	changelogUpdater *ChangelogUpdater
+	releaseCreator   ReleaseCreator
+	model            BranchingModel
+	dryRunStore      *DryRunFileStore
+
+	projectPath string
+
+	*BumpState
+}
+
+func (c *bumpContext) steps() []BumpStep {
+	steps := c.model.PrepareSteps(c)
+
+	steps = append(steps, []BumpStep{
+		{
+			Name: "bump package version",
+			Run:  c.bumpPackageVersion,
+			Undo: c.git.RevertChanges,
+		},
+		{
+			Name: "update changelog",
+			Run:  c.updateChangelog,
+			Undo: c.git.RevertChanges,
+		},
+		{
+			Name: "confirm changes",
+			Run:  c.confirmChanges,
+		},
+		{
+			Name: "commit version bump",
+			Run:  c.commitVersionBump,
+			Undo: c.undoCommitVersionBump,
+		},
+	}...)
+
+	steps = append(steps, c.model.PublishSteps(c)...)
+
+	if len(c.developmentPackagers()) > 0 {
+		developmentBranch := c.model.DevelopmentBranch()
+
+		steps = append(steps, []BumpStep{
+			{
+				Name: fmt.Sprintf("commit next development version to %s", developmentBranch),
+				Run:  func() error { return c.commitDevelopmentVersion(developmentBranch) },
+			},
+			{
+				Name:      fmt.Sprintf("push %s", developmentBranch),
+				Run:       func() error { return c.git.PushBranch(developmentBranch) },
+				Published: true,
+			},
+		}...)
+	}
+
+	return append(steps, BumpStep{
+		Name:      "create release",
+		Run:       c.createRelease,
+		Published: true,
+	})
+}
+
+// developmentPackagers returns the packagers which should set the next development version after releasing. This
+// isn't done for hotfixes or maintenance releases, as the development branch will already have moved on to a later
+// version.
+func (c *bumpContext) developmentPackagers() []Packager {
+	if !c.conf.NextDevelopmentVersion || c.Hotfix || c.Maintenance || c.packager == nil {
+		return nil
+	}
+
+	var packagers []Packager
+	for _, packager := range splitPackagers(c.packager) {
+		if _, ok := packager.(DevelopmentVersioner); ok {
+			packagers = append(packagers, packager)
+		}
+	}
+
+	return packagers
+}
+
+func (c *bumpContext) createReleaseBranch() error {
+	log.Debug().Msgf("Creating branch %s", c.ReleaseBranch)
+	return c.git.CreateBranch(c.ReleaseBranch)
+}
+
+func (c *bumpContext) undoCreateReleaseBranch() error {
+	startBranch := c.model.StartBranch()
+	if err := c.git.CheckoutBranch(startBranch); err != nil {
+		return fmt.Errorf("error switching back to %s branch: %w", startBranch, err)
+	}
+
+	if !c.git.BranchExists(c.ReleaseBranch) {
+		return nil
+	}
+
+	if err := c.git.ForceDeleteBranch(c.ReleaseBranch); err != nil {
+		return fmt.Errorf("error deleting release branch: %w", err)
+	}
+
+	return nil
+}
+
+func (c *bumpContext) bumpPackageVersion() error {
+	if c.packager == nil {
+		log.Debug().Msg("No supported package file found - skipping package version bump")
+		return nil
+	}
+
+	for _, packager := range splitPackagers(c.packager) {
+		if tagVersioner, ok := packager.(TagVersioner); ok {
+			tagVersioner.SetTagVersion(c.LatestTag)
+		}
+	}
+
+	log.Debug().Msgf("Bumping package version from %s to %s", c.LatestTag, c.NewVersion)
+	if err := c.packager.BumpVersion(c.NewVersion); err != nil {
+		return fmt.Errorf("error bumping package version: %w", err)
+	}
+
+	for _, packageFilePath := range c.packager.PackageFilePaths() {
+		// Lock files in particular are sometimes ignored, in which case there's nothing to commit.
+		if ignored, err := c.git.IsIgnored(packageFilePath); err != nil {
+			return fmt.Errorf("error checking whether package file is ignored: %w", err)
+		} else if ignored {
+			log.Debug().Msgf("Not adding ignored package file %s", packageFilePath)
+			continue
+		}
+
+		if err := c.git.Add(packageFilePath); err != nil {
+			return fmt.Errorf("error adding package file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *bumpContext) updateChangelog() error {
+	log.Debug().Msgf("Shifting unreleased changelog notes to %s", c.NewVersion)
+	if err := c.changelogUpdater.Update(c.NewVersion); err != nil {
+		return fmt.Errorf("error updating changelog: %w", err)
+	}
+
+	if err := c.git.Add(c.changelogUpdater.FilePath()); err != nil {
+		return fmt.Errorf("error adding changelog: %w", err)
+	}
+
+	// Now that we've updated the changelog, we can pull out the section for the new version.
+	releaseNotes, err := c.changelogUpdater.GetVersionNotes(c.NewVersion)
+	if err != nil {
+		return fmt.Errorf("error getting version notes: %w", err)
+	}
+
+	c.ReleaseNotes = releaseNotes
+	return nil
+}
+
+func (c *bumpContext) confirmChanges() error {
+	if c.conf.DryRun {
+		diff, err := c.dryRunStore.Diff(c.projectPath)
+		if err != nil {
+			return fmt.Errorf("error generating dry run diff: %w", err)
+		}
+
+		fmt.Print(diff)
+		return nil
+	}
+
+	if c.conf.Force {
+		return nil
+	}
+
+	c.git.RunDiff(true)
+
+	confirmPrompt := promptui.Prompt{
+		Label:     fmt.Sprintf("About to bump version to %s - continue?", c.NewTag()),
+		IsConfirm: true,
+	}
+
+	shouldContinue, err := confirmPrompt.Run()
+	if errors.Is(err, promptui.ErrAbort) || errors.Is(err, promptui.ErrInterrupt) || strings.ToLower(shouldContinue) != "y" {
+		log.Debug().Msg("Cancelling bump")
+		return ErrBumpCancelled
+	} else if err != nil {
+		return fmt.Errorf("error confirming version bump: %w", err)
+	}
+
+	return nil
+}
+
+func (c *bumpContext) commitVersionBump() error {
+	preCommit, err := c.git.GetCommitHash("HEAD")
+	if err != nil {
+		return err
+	}
+	c.PreCommit = preCommit
+
+	log.Debug().Msg("Committing changes")
+	return c.git.Commit(fmt.Sprintf("Bump version to %s", c.NewTag()))
+}
+
+func (c *bumpContext) undoCommitVersionBump() error {
+	if c.PreCommit == "" {
+		return nil
+	}
+
+	// Any later steps will already have been undone, so we just need to move the branch back.
+	if err := c.git.CheckoutBranch(c.ReleaseBranch); err != nil {
+		return fmt.Errorf("error switching to %s branch: %w", c.ReleaseBranch, err)
+	}
+
+	return c.git.ResetHard(c.PreCommit)
+}
+
+func (c *bumpContext) mergeReleaseBranch(targetBranch string) error {
+	mainCommit, err := c.git.GetCommitHash(targetBranch)
+	if err != nil {
+		return err
+	}
+	c.MainCommit = mainCommit
+
+	if err := c.git.CheckoutBranch(targetBranch); err != nil {
+		return fmt.Errorf("error switching to %s branch: %w", targetBranch, err)
+	}
+
+	log.Debug().Msgf("Merging release branch %s into %s", c.ReleaseBranch, targetBranch)
+	if err := c.git.MergeBranch(c.ReleaseBranch); err != nil {
+		return fmt.Errorf("error merging release branch: %w", err)
+	}
+
+	return nil
+}
+
+func (c *bumpContext) undoMergeReleaseBranch(targetBranch string) error {
+	if c.MainCommit == "" {
+		// We didn't get as far as switching branches.
+		return nil
+	}
+
+	// Resetting also clears out any half-finished merge if the merge itself failed.
+	if err := c.git.CheckoutBranch(targetBranch); err != nil {
+		if err := c.git.ResetHard("HEAD"); err != nil {
+			return err
+		}
+
+		if err := c.git.CheckoutBranch(targetBranch); err != nil {
+			return fmt.Errorf("error switching to %s branch: %w", targetBranch, err)
+		}
+	}
+
+	return c.git.ResetHard(c.MainCommit)
+}
+
+func (c *bumpContext) deleteReleaseBranch() error {
+	// The release branch won't actually exist in a dry run.
+	releaseCommit, err := c.git.GetCommitHash(c.ReleaseBranch)
+	if err != nil && !c.conf.DryRun {
+		return err
+	}
+	c.ReleaseCommit = releaseCommit
+
+	log.Debug().Msgf("Deleting release branch %s", c.ReleaseBranch)
+	return c.git.DeleteBranch(c.ReleaseBranch)
+}
+
+func (c *bumpContext) undoDeleteReleaseBranch() error {
+	if c.ReleaseCommit == "" || c.git.BranchExists(c.ReleaseBranch) {
+		return nil
+	}
+
+	return c.git.CreateBranchAt(c.ReleaseBranch, c.ReleaseCommit)
+}
+
+func (c *bumpContext) tagRelease() error {
+	log.Debug().Msgf("Creating tag %s", c.NewTag())
+	if err := c.git.Tag(c.NewTag()); err != nil {
+		return err
+	}
+
+	c.TagCreated = true
+	return nil
+}
+
+func (c *bumpContext) undoTagRelease() error {
+	// Make sure we don't delete a tag which already existed before the bump.
+	if !c.TagCreated {
+		return nil
+	}
+
+	return c.git.DeleteTag(c.NewTag())
+}
+
+func (c *bumpContext) mergeBranch(sourceBranch string, targetBranch string) error {
+	if err := c.git.CheckoutBranch(targetBranch); err != nil {
+		return fmt.Errorf("error switching to %s branch: %w", targetBranch, err)
+	}
+
+	log.Debug().Msgf("Merging %s into %s", sourceBranch, targetBranch)
+	if err := c.git.MergeBranch(sourceBranch); err != nil {
+		return fmt.Errorf("error merging %s branch: %w", sourceBranch, err)
+	}
+
+	return nil
+}
+
+func (c *bumpContext) commitDevelopmentVersion(developmentBranch string) error {
+	if err := c.git.CheckoutBranch(developmentBranch); err != nil {
+		return fmt.Errorf("error switching to %s branch: %w", developmentBranch, err)
+	}
+
+	var developmentVersions []string
+	for _, packager := range c.developmentPackagers() {
+		developmentVersion, err := packager.(DevelopmentVersioner).DevelopmentVersion(c.NewVersion)
+		if err != nil {
+			return fmt.Errorf("error getting next %s development version: %w", packager.Name(), err)
+		}
+
+		log.Debug().Msgf("Setting %s development version to %s", packager.Name(), developmentVersion)
+		if err := packager.BumpVersion(developmentVersion); err != nil {
+			return fmt.Errorf("error setting %s development version: %w", packager.Name(), err)
+		}
+
+		if !slices.Contains(developmentVersions, developmentVersion) {
+			developmentVersions = append(developmentVersions, developmentVersion)
+		}
+	}
+
+	return c.git.Commit(
+		fmt.Sprintf("Prepare for next development iteration %s", strings.Join(developmentVersions, ", ")),
+	)
+}
+
+func (c *bumpContext) createRelease() error {
+	if c.conf.DryRun {
+		log.Info().Msgf(
+			"Dry run: would create %s release '%s %s' for tag %s (pre-release: %t) with notes:\n%s",
+			c.releaseCreator.Name(),
+			c.ProjectName,
+			c.NewVersion,
+			c.NewTag(),
+			c.Prerelease,
+			c.ReleaseNotes,
+		)
+		return nil
+	}
+
+	log.Debug().Msgf("Creating release in %s", c.releaseCreator.Name())
+	releaseURL, err := c.releaseCreator.CreateRelease(c.NewTag(), c.NewVersion, c.ReleaseNotes, c.Prerelease)
+	if err != nil {
+		return fmt.Errorf("error creating release: %w", err)
+	}
+
+	log.Info().Msgf("Created %s release: %s", c.releaseCreator.Name(), releaseURL.String())
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/Masterminds/semver"
@@ -12,51 +13,248 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-type BumpType int
-
-const (
-	BumpTypeMajor BumpType = iota
-	BumpTypeMinor
-	BumpTypePatch
-)
-
 type Bumper struct {
 	conf *Config
 }
 
 func (b *Bumper) Bump() error {
+	git := GitWrapper{DryRun: b.conf.DryRun}
+
+	journalPath, err := checkNoIncompleteBump(&git)
+	if err != nil {
+		return err
+	}
+
+	bump, err := b.newBumpContext(&git)
+	if err != nil {
+		return err
+	}
+
+	latestTag := bump.LatestTag
+
+	currentBranch, err := git.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("error getting current branch: %w", err)
+	}
+
+	model, err := NewBranchingModel(b.conf)
+	if err != nil {
+		return err
+	}
+
+	// Later releases of a release line are made from its release branch.
+	if b.conf.BranchingModel == BranchingModelReleaseBranch &&
+		strings.HasPrefix(currentBranch, b.conf.ReleaseBranchPrefix) {
+		model = newMaintenanceModel(b.conf, currentBranch)
+		bump.Maintenance = true
+	}
+
+	if currentBranch != model.StartBranch() {
+		return fmt.Errorf(
+			"expected current branch to be '%s' for %s branching model, got %s",
+			model.StartBranch(),
+			model.Name(),
+			currentBranch,
+		)
+	}
+
+	// Use the tag version as the last version as some packages don't contain versions.
+	lastVersion, err := semver.NewVersion(latestTag)
+	if err != nil {
+		return fmt.Errorf("error parsing last version: %w", err)
+	}
+
+	if b.conf.BumpType == nil {
+		suggestedType := b.suggestBumpType(bump.changelogUpdater)
+
+		bumpType, err := b.promptBumpType(lastVersion, suggestedType)
+		if err != nil {
+			return err
+		}
+
+		b.conf.BumpType = &bumpType
+	}
+
+	inferred := *b.conf.BumpType == BumpTypeAuto
+	if inferred {
+		bumpType, err := b.inferBumpType(&git, bump.PreviousTag())
+		if err != nil {
+			return err
+		}
+
+		b.conf.BumpType = &bumpType
+	}
+
+	bumpVersion, err := b.conf.BumpType.NextVersion(lastVersion, b.conf.PrereleaseID)
+	if err != nil {
+		return err
+	}
+
+	// Inferred bumps never skip over the final version of a pre-release, so make it clear that it's being released.
+	if inferred && lastVersion.Prerelease() != "" && bumpVersion.Prerelease() == "" {
+		log.Info().Msgf(
+			"%s is a pre-release, so the %s bump releases v%s",
+			bump.PreviousTag(),
+			b.conf.BumpType,
+			bumpVersion.String(),
+		)
+	}
+
+	newVersion := fmt.Sprintf("v%s", bumpVersion.String())
+
+	bump.model = model
+	bump.MergeRequest = b.conf.MergeRequest
+	bump.Prerelease = bumpVersion.Prerelease() != ""
+	bump.NewVersion = newVersion
+	bump.ReleaseBranch = model.ReleaseBranch(bump.NewTag())
+
+	if b.conf.BranchingModel == BranchingModelReleaseBranch {
+		if err := checkReleaseLine(&git, bump, currentBranch); err != nil {
+			return err
+		}
+	}
+
+	log.Info().Msgf("Bumping %s version from %s to %s", b.conf.BumpType, bump.PreviousTag(), bump.NewTag())
+
+	return b.runBump(bump, journalPath)
+}
+
+// suggestBumpType infers the bump type from the changelog if enabled in the config, to use as the default choice
+// in the prompt. Returns nil if there's no suggestion.
+func (b *Bumper) suggestBumpType(changelogUpdater *ChangelogUpdater) *BumpType {
+	if !b.conf.InferBumpFromChangelog {
+		return nil
+	}
+
+	subsections, err := changelogUpdater.GetUnreleasedSubsections()
+	if err != nil {
+		log.Warn().Err(err).Msg("Unable to infer bump type from changelog")
+		return nil
+	}
+
+	bumpType, reasons, err := inferBumpTypeFromChangelog(subsections)
+	if err != nil {
+		log.Warn().Err(err).Msg("Unable to infer bump type from changelog")
+		return nil
+	}
+
+	log.Info().Msgf("Suggesting %s bump from changelog sections: %s", bumpType, strings.Join(reasons, ", "))
+	return &bumpType
+}
+
+// promptBumpType asks the user which kind of version bump to do, with the suggested bump type (if any) selected by
+// default.
+func (b *Bumper) promptBumpType(lastVersion *semver.Version, suggestedType *BumpType) (BumpType, error) {
+	var choices []BumpType
+	var items []string
+	cursorPos := 0
+
+	for _, bumpType := range bumpTypeChoices(lastVersion) {
+		// e.g. the pre-release identifier can't go from rc back to beta.
+		nextVersion, err := bumpType.NextVersion(lastVersion, b.conf.PrereleaseID)
+		if err != nil {
+			log.Debug().Msgf("Not offering %s bump: %v", bumpType, err)
+			continue
+		}
+
+		item := fmt.Sprintf("%s (v%s)", bumpType.Label(), nextVersion.String())
+		if suggestedType != nil && bumpType == *suggestedType {
+			item += " [suggested]"
+			cursorPos = len(items)
+		}
+
+		choices = append(choices, bumpType)
+		items = append(items, item)
+	}
+
+	prompt := promptui.Select{
+		Label:     fmt.Sprintf("Select a version to bump to (current: v%s)", lastVersion),
+		Items:     items,
+		CursorPos: cursorPos,
+	}
+
+	resultIndex, _, err := prompt.Run()
+	if errors.Is(err, promptui.ErrInterrupt) {
+		return 0, fmt.Errorf("bump aborted")
+	} else if err != nil {
+		return 0, fmt.Errorf("error selecting version bump: %w", err)
+	}
+
+	return choices[resultIndex], nil
+}
+
+// inferBumpType picks the bump type from the Conventional Commits since the last tag, and shows which commits
+// triggered it. In monorepo mode, only commits which touch the package are considered.
+func (b *Bumper) inferBumpType(git *GitWrapper, latestTag string) (BumpType, error) {
+	var paths []string
+	if b.conf.PackagePath != "" {
+		paths = append(paths, b.conf.PackagePath)
+	}
+
+	commits, err := git.GetCommitsSince(latestTag, paths...)
+	if err != nil {
+		return 0, err
+	}
+
+	bumpType, reasons, err := inferBumpTypeFromCommits(commits)
+	if err != nil {
+		return 0, fmt.Errorf("unable to infer bump type from commits since %s: %w", latestTag, err)
+	}
+
+	var reasonLines strings.Builder
+	for _, reason := range reasons {
+		reasonLines.WriteString(fmt.Sprintf("\n  %s", reason))
+	}
+
+	log.Info().Msgf("Inferred %s bump from %d commit(s) since %s:%s", bumpType, len(reasons), latestTag, reasonLines.String())
+	return bumpType, nil
+}
+
+// newBumpContext works out the project details which are needed for any kind of bump.
+func (b *Bumper) newBumpContext(git *GitWrapper) (*bumpContext, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
-		return fmt.Errorf("error getting current working directory: %w", err)
+		return nil, fmt.Errorf("error getting current working directory: %w", err)
+	}
+
+	store, dryRunStore := b.fileStore()
+
+	projectPath, tagPrefix, err := b.packageLocation(cwd)
+	if err != nil {
+		return nil, err
 	}
 
-	git := GitWrapper{}
+	latestTag, err := git.GetLatestTag(tagPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("error getting latest tag: %w", err)
+	}
+
+	// The prefix is added back on whenever the tag itself is needed.
+	latestTag = strings.TrimPrefix(latestTag, tagPrefix)
 
-	latestTag, err := git.GetLatestTag()
+	packager, err := packagerForProject(projectPath, store, b.conf)
 	if err != nil {
-		return fmt.Errorf("error getting latest tag: %w", err)
+		return nil, err
 	}
 
-	packager := packagerForProject(cwd)
 	if packager == nil {
 		log.Warn().Msg("No supported package file found - package version will not be bumped")
 	} else {
-		if packager.Version() != "" && packager.Version() != latestTag {
-			return fmt.Errorf("latest tag %s does not match package version %s", latestTag, packager.Version())
+		if packager.Version() != "" && !sameVersion(packager.Version(), latestTag) {
+			return nil, fmt.Errorf("latest tag %s does not match package version %s", latestTag, packager.Version())
 		}
 	}
 
-	changelogUpdater := NewChangelogUpdater(cwd)
+	changelogUpdater := NewChangelogUpdater(projectPath, store)
 
-	readmeParser := NewReadmeParser(cwd)
-	projectName, err := readmeParser.GetProjectName()
+	projectName, err := b.projectName(projectPath, tagPrefix)
 	if err != nil {
-		return fmt.Errorf("error getting project name: %w", err)
+		return nil, err
 	}
 
 	releaseCreator, err := getReleaseCreator(projectName, b.conf)
 	if err != nil {
-		return fmt.Errorf("error getting release creator: %w", err)
+		return nil, fmt.Errorf("error getting release creator: %w", err)
 	}
 
 	packagerName := "none"
@@ -71,191 +269,213 @@ func (b *Bumper) Bump() error {
 		packagerName,
 	)
 
-	currentBranch, err := git.GetCurrentBranch()
-	if err != nil {
-		return fmt.Errorf("error getting current branch: %w", err)
-	}
-
-	if currentBranch != "dev" {
-		return fmt.Errorf("expected current branch to be 'dev', got %s", currentBranch)
-	}
-
 	if hasChanges, err := git.HasUncommittedChanges(); err != nil {
-		return fmt.Errorf("error checking for uncommitted changes: %w", err)
+		return nil, fmt.Errorf("error checking for uncommitted changes: %w", err)
 	} else if hasChanges {
-		return errors.New("uncommitted changes found - commit / stash changes before bumping version")
+		return nil, errors.New("uncommitted changes found - commit / stash changes before bumping version")
 	}
 
-	// Use the tag version as the last version as some packages don't contain versions.
-	lastVersion, err := semver.NewVersion(latestTag)
-	if err != nil {
-		return fmt.Errorf("error parsing last version: %w", err)
-	}
+	return &bumpContext{
+		conf:             b.conf,
+		git:              git,
+		packager:         packager,
+		changelogUpdater: changelogUpdater,
+		releaseCreator:   releaseCreator,
+		dryRunStore:      dryRunStore,
+		projectPath:      cwd,
+		BumpState: &BumpState{
+			ProjectName: projectName,
+			LatestTag:   latestTag,
+			PackagePath: b.conf.PackagePath,
+			TagPrefix:   tagPrefix,
+		},
+	}, nil
+}
 
-	majorBumpVersion := lastVersion.IncMajor()
-	minorBumpVersion := lastVersion.IncMinor()
-	patchBumpVersion := lastVersion.IncPatch()
+// fileStore returns the store for the packagers and changelog to use. In a dry run, this keeps changes in memory
+// and is also returned as a DryRunFileStore so that the changes can be shown.
+func (b *Bumper) fileStore() (FileStore, *DryRunFileStore) {
+	if !b.conf.DryRun {
+		return &DiskFileStore{}, nil
+	}
 
-	if b.conf.BumpType == nil {
-		// Prompt for whether to do major, minor or patch bump.
-		prompt := promptui.Select{
-			Label: fmt.Sprintf("Select a version to bump to (current: v%s)", lastVersion),
-			Items: []string{
-				fmt.Sprintf("Major (v%s)", majorBumpVersion.String()),
-				fmt.Sprintf("Minor (v%s)", minorBumpVersion.String()),
-				fmt.Sprintf("Patch (v%s)", patchBumpVersion.String()),
-			},
-		}
+	log.Info().Msg("Dry run: no changes will be made to the working tree, remote or release server")
+	dryRunStore := NewDryRunFileStore()
+	return dryRunStore, dryRunStore
+}
 
-		resultIndex, _, err := prompt.Run()
-		if errors.Is(err, promptui.ErrInterrupt) {
-			return fmt.Errorf("bump aborted")
-		} else if err != nil {
-			return fmt.Errorf("error selecting version bump: %w", err)
-		}
+// packageLocation returns the directory containing the package to bump and the prefix of its tags. Outside of
+// monorepo mode this is just the current directory, and tags don't have a prefix.
+func (b *Bumper) packageLocation(cwd string) (string, string, error) {
+	if b.conf.PackagePath == "" {
+		return cwd, "", nil
+	}
 
-		b.conf.BumpType = Ptr(BumpType(resultIndex))
-	}
-
-	var bumpVersion semver.Version
-	var bumpText string
-	switch *b.conf.BumpType {
-	case BumpTypeMajor:
-		bumpVersion = majorBumpVersion
-		bumpText = "major"
-	case BumpTypeMinor:
-		bumpVersion = minorBumpVersion
-		bumpText = "minor"
-	case BumpTypePatch:
-		bumpVersion = patchBumpVersion
-		bumpText = "patch"
-	default:
-		return errors.New("invalid version bump selection")
+	packagePath := path.Clean(filepath.ToSlash(b.conf.PackagePath))
+	if packagePath == "." {
+		return cwd, "", nil
 	}
 
-	newVersion := fmt.Sprintf("v%s", bumpVersion.String())
-	log.Info().Msgf("Bumping %s version from %s to %s", bumpText, latestTag, newVersion)
+	if path.IsAbs(packagePath) || packagePath == ".." || strings.HasPrefix(packagePath, "../") {
+		return "", "", fmt.Errorf("package path %s must be inside the repository", b.conf.PackagePath)
+	}
 
-	releaseBranchName := fmt.Sprintf("release/%s", newVersion)
-	log.Debug().Msgf("Creating branch %s", releaseBranchName)
-	if err := git.CreateBranch(releaseBranchName); err != nil {
-		return fmt.Errorf("error creating release branch: %w", err)
+	projectPath := path.Join(cwd, packagePath)
+	if info, err := os.Stat(projectPath); err != nil || !info.IsDir() {
+		return "", "", fmt.Errorf("package directory %s not found", packagePath)
 	}
 
-	if packager != nil {
-		log.Debug().Msgf("Bumping package version from %s to %s", latestTag, newVersion)
-		if err := packager.BumpVersion(newVersion); err != nil {
-			return fmt.Errorf("error bumping package version: %w", err)
-		}
+	// Tags are prefixed with the full path of the package, in the same way as Go modules in subdirectories, so that
+	// packages with the same directory name don't share tags, e.g. packages/api is tagged as packages/api/v1.4.0.
+	return projectPath, packagePath + "/", nil
+}
 
-		if err := git.Add(packager.PackageFilePath()); err != nil {
-			return fmt.Errorf("error adding package file: %w", err)
-		}
-	} else {
-		log.Debug().Msg("No supported package file found - skipping package version bump")
+// projectName gets the name to use for releases from the h1 of the README. Packages in a monorepo often don't have
+// their own README, in which case the package is named after its directory.
+func (b *Bumper) projectName(projectPath string, tagPrefix string) (string, error) {
+	projectName, err := NewReadmeParser(projectPath).GetProjectName()
+	if err != nil && tagPrefix != "" {
+		return path.Base(tagPrefix), nil
+	} else if err != nil {
+		return "", fmt.Errorf("error getting project name: %w", err)
 	}
 
-	log.Debug().Msgf("Shifting unreleased changelog notes to %s", newVersion)
-	if err := changelogUpdater.Update(newVersion); err != nil {
-		return fmt.Errorf("error updating changelog: %w", err)
-	}
+	return projectName, nil
+}
 
-	if err := git.Add(path.Join(cwd, "CHANGELOG.md")); err != nil {
-		return fmt.Errorf("error adding changelog: %w", err)
+// runBump runs all the steps of a bump once the new version and branching model have been decided.
+func (b *Bumper) runBump(bump *bumpContext, journalPath string) error {
+	// Nothing needs resuming after a dry run.
+	if b.conf.DryRun {
+		journalPath = ""
 	}
 
-	// Now that we've updated the changelog, we can pull out the section for the new version.
-	releaseNotes, err := changelogUpdater.GetVersionNotes(newVersion)
+	journal, err := NewBumpJournal(bump.steps(), bump.BumpState, journalPath)
 	if err != nil {
-		return fmt.Errorf("error getting version notes: %w", err)
+		return err
 	}
 
-	if !b.conf.Force {
-		git.RunDiff(true)
-
-		confirmPrompt := promptui.Prompt{
-			Label:     fmt.Sprintf("About to bump version to %s - continue?", newVersion),
-			IsConfirm: true,
-		}
+	if err := journal.Run(); errors.Is(err, ErrBumpPaused) {
+		return nil
+	} else if err != nil {
+		return err
+	}
 
-		shouldContinue, err := confirmPrompt.Run()
-		if errors.Is(err, promptui.ErrAbort) || errors.Is(err, promptui.ErrInterrupt) || strings.ToLower(shouldContinue) != "y" {
-			log.Debug().Msg("Cancelling bump")
+	if b.conf.DryRun {
+		log.Info().Msgf(
+			"Dry run complete - would have bumped version from %s to %s",
+			bump.PreviousTag(),
+			bump.NewTag(),
+		)
+		return nil
+	}
 
-			if err := git.RevertChanges(); err != nil {
-				return fmt.Errorf("error reverting staged changes: %w", err)
-			}
+	log.Info().Msgf("Successfully bumped version from %s to %s", bump.PreviousTag(), bump.NewTag())
+	return nil
+}
 
-			if err := git.CheckoutBranch("dev"); err != nil {
-				return fmt.Errorf("error switching back to dev branch: %w", err)
-			}
+// Resume continues a bump which previously failed after changes were published, picking up from the first step
+// which didn't complete.
+func (b *Bumper) Resume() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("error getting current working directory: %w", err)
+	}
 
-			if err := git.DeleteBranch(releaseBranchName); err != nil {
-				return fmt.Errorf("error deleting release branch: %w", err)
-			}
+	git := GitWrapper{DryRun: b.conf.DryRun}
 
-			return errors.New("version bump cancelled")
-		} else if err != nil {
-			return fmt.Errorf("error confirming version bump: %w", err)
-		}
+	journalPath, err := bumpJournalPath(&git)
+	if err != nil {
+		return err
 	}
 
-	log.Debug().Msg("Committing changes")
-	if err := git.Commit(fmt.Sprintf("Bump version to %s", newVersion)); err != nil {
-		return fmt.Errorf("error committing version bump: %w", err)
+	if !fileExists(journalPath) {
+		return errors.New("no incomplete bump found to resume")
 	}
 
-	if err := git.CheckoutBranch("main"); err != nil {
-		return fmt.Errorf("error switching to main branch: %w", err)
+	state, err := LoadBumpState(journalPath)
+	if err != nil {
+		return err
 	}
 
-	log.Debug().Msgf("Merging release branch %s into main", releaseBranchName)
-	if err := git.MergeBranch(releaseBranchName); err != nil {
-		return fmt.Errorf("error merging release branch: %w", err)
-	}
+	store, dryRunStore := b.fileStore()
+	projectPath := path.Join(cwd, state.PackagePath)
 
-	log.Debug().Msgf("Deleting release branch %s", releaseBranchName)
-	if err := git.DeleteBranch(releaseBranchName); err != nil {
-		return fmt.Errorf("error deleting release branch: %w", err)
+	// The package version has normally already been bumped by this point, so we don't check it against the tag.
+	packager, err := packagerForProject(projectPath, store, b.conf)
+	if err != nil {
+		return err
 	}
 
-	log.Debug().Msgf("Creating tag %s", newVersion)
-	if err := git.Tag(newVersion); err != nil {
-		return fmt.Errorf("error tagging: %w", err)
+	releaseCreator, err := getReleaseCreator(state.ProjectName, b.conf)
+	if err != nil {
+		return fmt.Errorf("error getting release creator: %w", err)
 	}
 
-	log.Debug().Msg("Pushing commits")
-	if err := git.Push(); err != nil {
-		return fmt.Errorf("error pushing: %w", err)
-	}
+	// The branching model steps depend on whether a merge request was used, so this needs to match the original
+	// bump rather than the current config.
+	b.conf.MergeRequest = state.MergeRequest
 
-	log.Debug().Msg("Pushing tags")
-	if err := git.PushTags(); err != nil {
-		return fmt.Errorf("error pushing tags: %w", err)
+	var model BranchingModel
+	switch {
+	case state.Hotfix:
+		model = newHotfixModel(b.conf, state.ReleaseBranch, state.Maintenance)
+	case state.Maintenance:
+		model = newMaintenanceModel(b.conf, state.ReleaseBranch)
+	default:
+		model, err = NewBranchingModel(b.conf)
+		if err != nil {
+			return err
+		}
 	}
 
-	if err := git.CheckoutBranch("dev"); err != nil {
-		return fmt.Errorf("error switching to dev branch: %w", err)
+	bump := &bumpContext{
+		conf:             b.conf,
+		git:              &git,
+		packager:         packager,
+		changelogUpdater: NewChangelogUpdater(projectPath, store),
+		releaseCreator:   releaseCreator,
+		model:            model,
+		dryRunStore:      dryRunStore,
+		projectPath:      cwd,
+		BumpState:        state,
 	}
 
-	log.Debug().Msg("Merging main into dev")
-	if err := git.MergeBranch("main"); err != nil {
-		return fmt.Errorf("error merging main branch: %w", err)
+	// In a dry run, the journal is left as it is so that the bump can still be resumed for real.
+	return b.runBump(bump, journalPath)
+}
+
+// checkNoIncompleteBump makes sure that there's no journal left over from a previous bump which failed part-way
+// through, and returns the path to use for the journal.
+func checkNoIncompleteBump(git *GitWrapper) (string, error) {
+	journalPath, err := bumpJournalPath(git)
+	if err != nil {
+		return "", err
 	}
 
-	log.Debug().Msg("Pushing dev commits")
-	if err := git.Push(); err != nil {
-		return fmt.Errorf("error pushing: %w", err)
+	if fileExists(journalPath) {
+		state, err := LoadBumpState(journalPath)
+		if err != nil {
+			return "", err
+		}
+
+		return "", fmt.Errorf(
+			"previous bump to %s is incomplete - run `bumper resume` to finish it, or delete %s to discard it",
+			state.NewTag(),
+			journalPath,
+		)
 	}
 
-	log.Debug().Msgf("Creating release in %s", releaseCreator.Name())
-	releaseURL, err := releaseCreator.CreateRelease(newVersion, releaseNotes)
+	return journalPath, nil
+}
+
+// bumpJournalPath returns the path to the journal file for the current repository. This is kept inside the git
+// directory so that it doesn't show up as an uncommitted change.
+func bumpJournalPath(git *GitWrapper) (string, error) {
+	gitDir, err := git.GetGitDir()
 	if err != nil {
-		return fmt.Errorf("error creating release: %w", err)
+		return "", err
 	}
 
-	log.Info().Msgf("Created %s release: %s", releaseCreator.Name(), releaseURL.String())
-
-	log.Info().Msgf("Successfully bumped version from %s to %s", latestTag, newVersion)
-	return nil
+	return path.Join(gitDir, "bumper-journal.json"), nil
 }
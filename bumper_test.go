@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+)
+
+// newTestRepo creates a git repository with a single commit on main and a GitHub origin remote, and changes into
+// it for the rest of the test.
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+
+	repoPath := t.TempDir()
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(repoPath); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	writeTestFile(t, "README.md", "# Demo\n")
+
+	runGit(t, "init", "-q", "-b", "main")
+	runGit(t, "add", "-A")
+	runGit(t, "commit", "-q", "-m", "Initial commit")
+	runGit(t, "tag", "v1.0.0")
+	runGit(t, "remote", "add", "origin", "git@github.com:example/demo.git")
+
+	return repoPath
+}
+
+func runGit(t *testing.T, args ...string) string {
+	t.Helper()
+
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+
+	return string(bytes.TrimSpace(output))
+}
+
+func writeTestFile(t *testing.T, filePath string, contents string) {
+	t.Helper()
+
+	if err := os.MkdirAll(path.Dir(filePath), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filePath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertFileContents(t *testing.T, filePath string, want string) {
+	t.Helper()
+
+	got, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != want {
+		t.Errorf("%s = %q, want %q", filePath, got, want)
+	}
+}
+
+func TestResumeDryRunKeepsJournal(t *testing.T) {
+	repoPath := newTestRepo(t)
+
+	conf := &Config{
+		DryRun:              true,
+		BranchingModel:      BranchingModelGitFlow,
+		MainBranch:          "main",
+		DevBranch:           "dev",
+		ReleaseBranchPrefix: "release/",
+	}
+
+	model, err := NewBranchingModel(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := &BumpState{
+		ProjectName:   "Demo",
+		LatestTag:     "v1.0.0",
+		NewVersion:    "v1.1.0",
+		ReleaseBranch: "release/v1.1.0",
+		ReleaseNotes:  "## v1.1.0\n",
+	}
+
+	// Everything apart from creating the release has already been done.
+	steps := (&bumpContext{conf: conf, git: &GitWrapper{}, model: model, BumpState: state}).steps()
+	for _, step := range steps[:len(steps)-1] {
+		state.CompletedSteps = append(state.CompletedSteps, step.Name)
+	}
+
+	journalBytes, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	journalPath := path.Join(repoPath, ".git", "bumper-journal.json")
+	writeTestFile(t, journalPath, string(journalBytes))
+
+	if err := (&Bumper{conf: conf}).Resume(); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+
+	resumedJournalBytes, err := os.ReadFile(journalPath)
+	if err != nil {
+		t.Fatalf("journal removed by dry run: %v", err)
+	}
+
+	if !bytes.Equal(resumedJournalBytes, journalBytes) {
+		t.Errorf("journal changed by dry run:\n%s", resumedJournalBytes)
+	}
+}
+
+func TestPackageLocation(t *testing.T) {
+	cwd := t.TempDir()
+	for _, dirPath := range []string{"services/api", "libs/api"} {
+		if err := os.MkdirAll(path.Join(cwd, dirPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		packagePath     string
+		wantProjectPath string
+		wantTagPrefix   string
+		wantErr         bool
+	}{
+		{"", cwd, "", false},
+		{".", cwd, "", false},
+		{"services/api", path.Join(cwd, "services/api"), "services/api/", false},
+		{"./services//api/", path.Join(cwd, "services/api"), "services/api/", false},
+		{"libs/api", path.Join(cwd, "libs/api"), "libs/api/", false},
+		{"services/../libs/api", path.Join(cwd, "libs/api"), "libs/api/", false},
+		{"services/web", "", "", true},
+		{"../api", "", "", true},
+		{"/services/api", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.packagePath, func(t *testing.T) {
+			b := &Bumper{conf: &Config{PackagePath: tt.packagePath}}
+
+			projectPath, tagPrefix, err := b.packageLocation(cwd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("packageLocation() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			if projectPath != tt.wantProjectPath || tagPrefix != tt.wantTagPrefix {
+				t.Errorf(
+					"packageLocation() = %s, %s, want %s, %s",
+					projectPath,
+					tagPrefix,
+					tt.wantProjectPath,
+					tt.wantTagPrefix,
+				)
+			}
+		})
+	}
+}
+
+func TestGetLatestTagWithPrefix(t *testing.T) {
+	newTestRepo(t)
+
+	for _, tag := range []string{"services/api/v1.2.0", "libs/api/v3.0.0", "services/api/client/v5.0.0", "api/v9.0.0"} {
+		runGit(t, "commit", "-q", "--allow-empty", "-m", "Release "+tag)
+		runGit(t, "tag", tag)
+	}
+
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"", "v1.0.0"},
+		{"services/api/", "services/api/v1.2.0"},
+		{"libs/api/", "libs/api/v3.0.0"},
+		{"api/", "api/v9.0.0"},
+	}
+
+	for _, tt := range tests {
+		got, err := (&GitWrapper{}).GetLatestTag(tt.prefix)
+		if err != nil {
+			t.Fatalf("GetLatestTag(%q) error = %v", tt.prefix, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("GetLatestTag(%q) = %s, want %s", tt.prefix, got, tt.want)
+		}
+	}
+}
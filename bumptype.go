@@ -1 +1,193 @@
 package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+type BumpType int
+
+const (
+	BumpTypeMajor BumpType = iota
+	BumpTypeMinor
+	BumpTypePatch
+
+	// Pre-release bumps create a pre-release of the next major / minor / patch version, e.g. v2.0.0-rc.1.
+	BumpTypePreMajor
+	BumpTypePreMinor
+	BumpTypePrePatch
+
+	// BumpTypePrerelease increments the pre-release number, e.g. v2.0.0-rc.1 to v2.0.0-rc.2.
+	BumpTypePrerelease
+
+	// BumpTypeRelease promotes a pre-release to the final version, e.g. v2.0.0-rc.2 to v2.0.0.
+	BumpTypeRelease
+
+	// BumpTypeAuto works out whether to do a major, minor or patch bump from the commit messages since the last
+	// tag, using Conventional Commits.
+	BumpTypeAuto
+)
+
+var bumpTypeNames = map[BumpType]string{
+	BumpTypeMajor:      "major",
+	BumpTypeMinor:      "minor",
+	BumpTypePatch:      "patch",
+	BumpTypePreMajor:   "premajor",
+	BumpTypePreMinor:   "preminor",
+	BumpTypePrePatch:   "prepatch",
+	BumpTypePrerelease: "prerelease",
+	BumpTypeRelease:    "release",
+	BumpTypeAuto:       "auto",
+}
+
+var bumpTypeLabels = map[BumpType]string{
+	BumpTypeMajor:      "Major",
+	BumpTypeMinor:      "Minor",
+	BumpTypePatch:      "Patch",
+	BumpTypePreMajor:   "Pre-release major",
+	BumpTypePreMinor:   "Pre-release minor",
+	BumpTypePrePatch:   "Pre-release patch",
+	BumpTypePrerelease: "Next pre-release",
+	BumpTypeRelease:    "Release",
+	BumpTypeAuto:       "Automatic",
+}
+
+func (t BumpType) String() string {
+	if name, ok := bumpTypeNames[t]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("BumpType(%d)", int(t))
+}
+
+// Label is the human-readable name of the bump type, used in prompts.
+func (t BumpType) Label() string {
+	return bumpTypeLabels[t]
+}
+
+func ParseBumpType(name string) (BumpType, error) {
+	for bumpType, bumpTypeName := range bumpTypeNames {
+		if strings.ToLower(name) == bumpTypeName {
+			return bumpType, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid bump type: %s", name)
+}
+
+// NextVersion applies the bump to the version. The pre-release identifier (e.g. "alpha", "beta" or "rc") is only
+// used for pre-release bumps.
+//
+// Major, minor and patch bumps of a pre-release work in the same way as npm: if the pre-release is already of that
+// kind of version, it's promoted to the final version rather than skipping over it, e.g. a minor bump of
+// v2.1.0-rc.1 gives v2.1.0 but a major bump gives v3.0.0.
+func (t BumpType) NextVersion(version *semver.Version, preid string) (semver.Version, error) {
+	isPrerelease := version.Prerelease() != ""
+
+	switch t {
+	case BumpTypeMajor:
+		if isPrerelease && version.Minor() == 0 && version.Patch() == 0 {
+			return version.SetPrerelease("")
+		}
+
+		return version.IncMajor(), nil
+	case BumpTypeMinor:
+		if isPrerelease && version.Patch() == 0 {
+			return version.SetPrerelease("")
+		}
+
+		return version.IncMinor(), nil
+	case BumpTypePatch:
+		// IncPatch on a pre-release already just drops the pre-release.
+		return version.IncPatch(), nil
+	case BumpTypePreMajor:
+		return version.IncMajor().SetPrerelease(preid + ".1")
+	case BumpTypePreMinor:
+		return version.IncMinor().SetPrerelease(preid + ".1")
+	case BumpTypePrePatch:
+		nextVersion := version.IncPatch()
+		if isPrerelease {
+			// IncPatch on a pre-release just drops the pre-release, so make sure we actually move on a version.
+			nextVersion = nextVersion.IncPatch()
+		}
+
+		return nextVersion.SetPrerelease(preid + ".1")
+	case BumpTypePrerelease:
+		return nextPrerelease(version, preid)
+	case BumpTypeRelease:
+		if !isPrerelease {
+			return semver.Version{}, fmt.Errorf("v%s is not a pre-release", version)
+		}
+
+		return version.SetPrerelease("")
+	default:
+		return semver.Version{}, errors.New("invalid version bump selection")
+	}
+}
+
+// nextPrerelease increments the number on the end of the pre-release, e.g. rc.1 to rc.2. If the version isn't a
+// pre-release yet, it starts a pre-release of the next patch version instead. If the identifier is changing
+// (e.g. from beta to rc), numbering starts again from 1, which is only allowed if the new identifier sorts after
+// the current one.
+func nextPrerelease(version *semver.Version, preid string) (semver.Version, error) {
+	if version.Prerelease() == "" {
+		return BumpTypePrePatch.NextVersion(version, preid)
+	}
+
+	currentID, currentNumber, found := strings.Cut(version.Prerelease(), ".")
+	if !found || currentID != preid {
+		nextVersion, err := version.SetPrerelease(preid + ".1")
+		if err != nil {
+			return semver.Version{}, err
+		}
+
+		if !nextVersion.GreaterThan(version) {
+			return semver.Version{}, fmt.Errorf(
+				"pre-release v%s would come before v%s - use a pre-release identifier which sorts after %s",
+				nextVersion.String(),
+				version,
+				currentID,
+			)
+		}
+
+		return nextVersion, nil
+	}
+
+	number, err := strconv.Atoi(currentNumber)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("unable to increment pre-release %s: %w", version.Prerelease(), err)
+	}
+
+	return version.SetPrerelease(fmt.Sprintf("%s.%d", preid, number+1))
+}
+
+// bumpTypeChoices returns the bump types which make sense for the version, in the order they're shown in the
+// prompt. For pre-releases, major and minor bumps are only included if they go past the final version of the
+// pre-release, as otherwise they're the same as releasing it.
+func bumpTypeChoices(version *semver.Version) []BumpType {
+	if version.Prerelease() != "" {
+		choices := []BumpType{BumpTypePrerelease, BumpTypeRelease}
+
+		releaseVersion, _ := version.SetPrerelease("")
+		for _, bumpType := range []BumpType{BumpTypeMajor, BumpTypeMinor} {
+			if nextVersion, err := bumpType.NextVersion(version, ""); err == nil && !nextVersion.Equal(&releaseVersion) {
+				choices = append(choices, bumpType)
+			}
+		}
+
+		return choices
+	}
+
+	return []BumpType{
+		BumpTypeMajor,
+		BumpTypeMinor,
+		BumpTypePatch,
+		BumpTypePreMajor,
+		BumpTypePreMinor,
+		BumpTypePrePatch,
+	}
+}
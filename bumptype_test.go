@@ -0,0 +1,83 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Masterminds/semver"
+)
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		version  string
+		bumpType BumpType
+		preid    string
+		want     string
+		wantErr  bool
+	}{
+		{"1.2.3", BumpTypeMajor, "rc", "2.0.0", false},
+		{"1.2.3", BumpTypeMinor, "rc", "1.3.0", false},
+		{"1.2.3", BumpTypePatch, "rc", "1.2.4", false},
+		{"1.2.3", BumpTypePreMajor, "rc", "2.0.0-rc.1", false},
+		{"1.2.3", BumpTypePreMinor, "beta", "1.3.0-beta.1", false},
+		{"1.2.3", BumpTypePrePatch, "rc", "1.2.4-rc.1", false},
+		{"1.2.3", BumpTypePrerelease, "rc", "1.2.4-rc.1", false},
+		{"1.2.3", BumpTypeRelease, "rc", "", true},
+
+		// Pre-releases are promoted rather than skipped over if they're already at the level of the bump.
+		{"2.0.0-rc.1", BumpTypeMajor, "rc", "2.0.0", false},
+		{"2.0.0-rc.1", BumpTypeMinor, "rc", "2.0.0", false},
+		{"2.0.0-rc.1", BumpTypePatch, "rc", "2.0.0", false},
+		{"2.1.0-rc.1", BumpTypeMajor, "rc", "3.0.0", false},
+		{"2.1.0-rc.1", BumpTypeMinor, "rc", "2.1.0", false},
+		{"2.1.1-rc.1", BumpTypeMinor, "rc", "2.2.0", false},
+		{"2.1.1-rc.1", BumpTypePatch, "rc", "2.1.1", false},
+		{"2.0.0-rc.1", BumpTypePrePatch, "rc", "2.0.1-rc.1", false},
+		{"2.0.0-rc.1", BumpTypeRelease, "rc", "2.0.0", false},
+
+		{"2.0.0-rc.1", BumpTypePrerelease, "rc", "2.0.0-rc.2", false},
+		{"2.0.0-beta.3", BumpTypePrerelease, "rc", "2.0.0-rc.1", false},
+		{"2.0.0-alpha", BumpTypePrerelease, "beta", "2.0.0-beta.1", false},
+		{"2.0.0-rc.2", BumpTypePrerelease, "beta", "", true},
+		{"2.0.0-rc.2", BumpTypePrerelease, "alpha", "", true},
+	}
+
+	for _, tt := range tests {
+		version := semver.MustParse(tt.version)
+
+		got, err := tt.bumpType.NextVersion(version, tt.preid)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s bump of %s with preid %s = %s, want error", tt.bumpType, tt.version, tt.preid, got.String())
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s bump of %s with preid %s: unexpected error %v", tt.bumpType, tt.version, tt.preid, err)
+		} else if got.String() != tt.want {
+			t.Errorf("%s bump of %s with preid %s = %s, want %s", tt.bumpType, tt.version, tt.preid, got.String(), tt.want)
+		}
+	}
+}
+
+func TestBumpTypeChoices(t *testing.T) {
+	tests := []struct {
+		version string
+		want    []BumpType
+	}{
+		{
+			"1.2.3",
+			[]BumpType{BumpTypeMajor, BumpTypeMinor, BumpTypePatch, BumpTypePreMajor, BumpTypePreMinor, BumpTypePrePatch},
+		},
+		{"2.0.0-rc.1", []BumpType{BumpTypePrerelease, BumpTypeRelease}},
+		{"2.1.0-rc.1", []BumpType{BumpTypePrerelease, BumpTypeRelease, BumpTypeMajor}},
+		{"2.1.1-rc.1", []BumpType{BumpTypePrerelease, BumpTypeRelease, BumpTypeMajor, BumpTypeMinor}},
+	}
+
+	for _, tt := range tests {
+		if got := bumpTypeChoices(semver.MustParse(tt.version)); !slices.Equal(got, tt.want) {
+			t.Errorf("bumpTypeChoices(%s) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
@@ -3,110 +3,345 @@ package main
 import (
 	"errors"
 	"fmt"
-	"io"
-	"io/fs"
-	"os"
 	"path"
 	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
-	"github.com/rs/zerolog/log"
 )
 
-var unreleasedHeaderRe = regexp.MustCompile(`(?m)^## (Unreleased|Development)$`)
+// Matches "## " section and "### " subsection headers, ignoring trailing whitespace.
+var changelogHeaderRe = regexp.MustCompile(`^(#{2,3})[ \t]+(.*?)[ \t]*$`)
 
-type ChangelogUpdater struct {
-	filePath string
+var unreleasedTitleRe = regexp.MustCompile(`(?i)^\[?(?:unreleased|development)\]?$`)
+
+// Matches release titles like "v1.2.3 - 1st January 2024", "[1.2.3] - 2024-01-01", "[1.2.3](link) - 2024-01-01"
+// or "1.2.3 (2024-01-01)". The groups are the opening bracket, the 'v' prefix, the version, and the date in one of
+// the two styles.
+var releaseTitleRe = regexp.MustCompile(
+	`^(\[)?(v)?([0-9]+\.[0-9]+[^\s\[\]()]*)\]?(?:\([^)]*\))?(?:\s*[-–—]\s*(.*?)|\s+\((.*?)\))?$`,
+)
+
+var isoDateRe = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+
+// Changelog is CHANGELOG.md split into its "## " sections, each of which is the unreleased section, a release or
+// something else. Every line keeps its original text and line ending, so the file is written back byte-for-byte
+// apart from the sections which have been changed.
+type Changelog struct {
+	// Everything before the first section, e.g. the title and description.
+	Preamble []string
+	Sections []*ChangelogSection
+
+	lineEnding string
 }
 
-func NewChangelogUpdater(projectPath string) *ChangelogUpdater {
-	return &ChangelogUpdater{filePath: path.Join(projectPath, "CHANGELOG.md")}
+// ChangelogSection is a "## " section of the changelog. The lines before the first "### " subsection are in Intro.
+type ChangelogSection struct {
+	Header      string
+	Unreleased  bool
+	Intro       []string
+	Subsections []*ChangelogSubsection
+
+	// Version and Date are empty for sections which aren't releases.
+	Version string
+	Date    string
+
+	// How the release header was written, so that new releases can follow the same style.
+	bracketed    bool
+	versionV     bool
+	dateInParens bool
 }
 
-func (c *ChangelogUpdater) GetVersionNotes(version string) (string, error) {
-	file, err := os.Open(c.filePath)
-	if err != nil {
-		return "", fmt.Errorf("error opening CHANGELOG.md: %w", err)
+// ChangelogSubsection is a "### " subsection, e.g. "### Added" in Keep a Changelog style changelogs.
+type ChangelogSubsection struct {
+	Header string
+	Name   string
+	Lines  []string
+}
+
+// ParseChangelog parses the changelog contents. This never fails, as anything which isn't understood is just kept
+// as it is.
+func ParseChangelog(contents string) *Changelog {
+	changelog := &Changelog{lineEnding: "\n"}
+	if strings.Contains(contents, "\r\n") {
+		changelog.lineEnding = "\r\n"
 	}
-	defer func(file *os.File) {
-		if err := file.Close(); err != nil {
-			log.Error().Err(err).Msg("error closing CHANGELOG.md")
+
+	var section *ChangelogSection
+	var subsection *ChangelogSubsection
+	inCodeBlock := false
+
+	for _, line := range strings.SplitAfter(contents, "\n") {
+		if line == "" {
+			continue
 		}
-	}(file)
 
-	changelogBytes, err := io.ReadAll(file)
-	if err != nil {
-		return "", fmt.Errorf("error reading CHANGELOG.md: %w", err)
+		text := strings.TrimRight(line, "\r\n")
+		trimmedText := strings.TrimSpace(text)
+
+		// Headers inside code blocks aren't really headers.
+		if strings.HasPrefix(trimmedText, "```") || strings.HasPrefix(trimmedText, "~~~") {
+			inCodeBlock = !inCodeBlock
+		} else if matches := changelogHeaderRe.FindStringSubmatch(text); matches != nil && !inCodeBlock {
+			if matches[1] == "##" {
+				section = newChangelogSection(line, matches[2])
+				subsection = nil
+				changelog.Sections = append(changelog.Sections, section)
+				continue
+			} else if section != nil {
+				subsection = &ChangelogSubsection{Header: line, Name: matches[2]}
+				section.Subsections = append(section.Subsections, subsection)
+				continue
+			}
+		}
+
+		switch {
+		case subsection != nil:
+			subsection.Lines = append(subsection.Lines, line)
+		case section != nil:
+			section.Intro = append(section.Intro, line)
+		default:
+			changelog.Preamble = append(changelog.Preamble, line)
+		}
 	}
 
-	changelogContents := string(changelogBytes)
+	return changelog
+}
 
-	versionSectionRe, err := regexp.Compile(
-		fmt.Sprintf(
-			`(?ms)^(## %s.*?\n.+?)(?:\n## |\z)`,
-			regexp.QuoteMeta(version),
-		),
-	)
-	if err != nil {
-		return "", fmt.Errorf("error compiling version section regex: %w", err)
+func newChangelogSection(header string, title string) *ChangelogSection {
+	section := &ChangelogSection{Header: header}
+
+	if unreleasedTitleRe.MatchString(title) {
+		section.Unreleased = true
+	} else if matches := releaseTitleRe.FindStringSubmatch(title); matches != nil {
+		section.Version = matches[3]
+		section.Date = matches[4] + matches[5]
+		section.bracketed = matches[1] != ""
+		section.versionV = matches[2] != ""
+		section.dateInParens = matches[5] != ""
 	}
 
-	// `matches[0]` contains full matching string, then rest of slice contains the capture groups in order.
-	matches := versionSectionRe.FindStringSubmatch(changelogContents)
-	if len(matches) != 2 {
-		return "", fmt.Errorf("section for %s not found in CHANGELOG.md", version)
+	return section
+}
+
+// String returns the changelog contents.
+func (c *Changelog) String() string {
+	var sb strings.Builder
+
+	for _, line := range c.Preamble {
+		sb.WriteString(line)
 	}
 
-	return matches[1], nil
+	for _, section := range c.Sections {
+		sb.WriteString(section.Header)
+		sb.WriteString(section.Body())
+	}
+
+	return sb.String()
 }
 
-func (c *ChangelogUpdater) Update(newVersion string) error {
-	file, err := os.Open(c.filePath)
-	if err != nil {
-		return fmt.Errorf("error opening CHANGELOG.md: %w", err)
+// Body returns the contents of the section after the header.
+func (s *ChangelogSection) Body() string {
+	var sb strings.Builder
+
+	for _, line := range s.Intro {
+		sb.WriteString(line)
 	}
-	defer func(file *os.File) {
-		if err := file.Close(); err != nil && !errors.Is(err, fs.ErrClosed) {
-			log.Error().Err(err).Msg("error closing CHANGELOG.md")
+
+	for _, subsection := range s.Subsections {
+		sb.WriteString(subsection.Header)
+		for _, line := range subsection.Lines {
+			sb.WriteString(line)
 		}
-	}(file)
+	}
 
-	changelogBytes, err := io.ReadAll(file)
-	if err != nil {
-		return fmt.Errorf("error reading CHANGELOG.md: %w", err)
+	return sb.String()
+}
+
+// Unreleased returns the unreleased section, or nil if there isn't one.
+func (c *Changelog) Unreleased() *ChangelogSection {
+	for _, section := range c.Sections {
+		if section.Unreleased {
+			return section
+		}
 	}
 
-	changelogContents := string(changelogBytes)
+	return nil
+}
 
-	// Take everything under unreleased header (either "Unreleased" or "Development") and put it under the
-	// new version header.
+// Release returns the section for the version, or nil if there isn't one. Versions match with or without a 'v'
+// prefix.
+func (c *Changelog) Release(version string) *ChangelogSection {
+	version = strings.TrimPrefix(version, "v")
 
-	now := time.Now()
-	currentDate := fmt.Sprintf(
-		"%s %s",
-		humanize.Ordinal(now.Day()),
-		now.Format("January 2006"),
-	)
+	for _, section := range c.Sections {
+		if section.Version != "" && section.Version == version {
+			return section
+		}
+	}
 
-	replacement := fmt.Sprintf("## $1\n\n–\n\n## %s - %s", newVersion, currentDate)
-	changelogContents = unreleasedHeaderRe.ReplaceAllString(changelogContents, replacement)
+	return nil
+}
 
-	if err := file.Close(); err != nil {
-		return fmt.Errorf("error closing CHANGELOG.md: %w", err)
+// AddRelease moves everything in the unreleased section into a new section for the version, leaving a placeholder
+// in the unreleased section.
+func (c *Changelog) AddRelease(version string, date time.Time) error {
+	unreleased := c.Unreleased()
+	if unreleased == nil {
+		return errors.New("unreleased section not found in CHANGELOG.md")
 	}
 
-	file, err = os.OpenFile(c.filePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if c.Release(version) != nil {
+		return fmt.Errorf("section for %s already exists in CHANGELOG.md", version)
+	}
+
+	release := c.newRelease(version, date)
+	release.Intro = unreleased.Intro
+	release.Subsections = unreleased.Subsections
+
+	if !strings.HasSuffix(unreleased.Header, "\n") {
+		unreleased.Header += c.lineEnding
+	}
+
+	unreleased.Intro = []string{c.lineEnding, "–" + c.lineEnding, c.lineEnding}
+	unreleased.Subsections = nil
+
+	unreleasedIndex := slices.Index(c.Sections, unreleased)
+	c.Sections = slices.Insert(c.Sections, unreleasedIndex+1, release)
+
+	return nil
+}
+
+// newRelease creates a release section, with the header written in the same style as the latest release. If
+// there aren't any releases yet, the header looks like "## v1.2.3 - 1st January 2024".
+func (c *Changelog) newRelease(version string, date time.Time) *ChangelogSection {
+	release := &ChangelogSection{
+		Version:  strings.TrimPrefix(version, "v"),
+		versionV: true,
+	}
+
+	var latestRelease *ChangelogSection
+	for _, section := range c.Sections {
+		if section.Version != "" {
+			latestRelease = section
+			break
+		}
+	}
+
+	isoDate := false
+	if latestRelease != nil {
+		release.bracketed = latestRelease.bracketed
+		release.versionV = latestRelease.versionV
+		release.dateInParens = latestRelease.dateInParens
+		isoDate = isoDateRe.MatchString(latestRelease.Date)
+	}
+
+	release.Date = fmt.Sprintf("%s %s", humanize.Ordinal(date.Day()), date.Format("January 2006"))
+	if isoDate {
+		release.Date = date.Format("2006-01-02")
+	}
+
+	versionStr := release.Version
+	if release.versionV {
+		versionStr = "v" + versionStr
+	}
+
+	if release.bracketed {
+		versionStr = "[" + versionStr + "]"
+	}
+
+	if release.dateInParens {
+		release.Header = fmt.Sprintf("## %s (%s)%s", versionStr, release.Date, c.lineEnding)
+	} else {
+		release.Header = fmt.Sprintf("## %s - %s%s", versionStr, release.Date, c.lineEnding)
+	}
+
+	return release
+}
+
+type ChangelogUpdater struct {
+	store    FileStore
+	filePath string
+}
+
+func NewChangelogUpdater(projectPath string, store FileStore) *ChangelogUpdater {
+	return &ChangelogUpdater{store: store, filePath: path.Join(projectPath, "CHANGELOG.md")}
+}
+
+// FilePath returns the path to the CHANGELOG.md file.
+func (c *ChangelogUpdater) FilePath() string {
+	return c.filePath
+}
+
+func (c *ChangelogUpdater) read() (*Changelog, error) {
+	changelogBytes, err := c.store.ReadFile(c.filePath)
 	if err != nil {
-		return fmt.Errorf("error opening CHANGELOG.md for writing: %w", err)
+		return nil, fmt.Errorf("error reading CHANGELOG.md: %w", err)
 	}
-	defer func(file *os.File) {
-		if err := file.Close(); err != nil {
-			log.Error().Err(err).Msg("error closing CHANGELOG.md")
+
+	return ParseChangelog(string(changelogBytes)), nil
+}
+
+// GetVersionNotes returns the section for the version, including the header, to use as the release notes.
+func (c *ChangelogUpdater) GetVersionNotes(version string) (string, error) {
+	changelog, err := c.read()
+	if err != nil {
+		return "", err
+	}
+
+	release := changelog.Release(version)
+	if release == nil {
+		return "", fmt.Errorf("section for %s not found in CHANGELOG.md", version)
+	}
+
+	notes := strings.ReplaceAll(release.Header+release.Body(), "\r\n", "\n")
+	return strings.TrimRight(notes, " \t\n") + "\n", nil
+}
+
+// GetUnreleasedSubsections returns the names of the "### " subsections of the unreleased section which have any
+// content, e.g. "Added" or "Fixed" for Keep a Changelog style changelogs.
+func (c *ChangelogUpdater) GetUnreleasedSubsections() ([]string, error) {
+	changelog, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+
+	unreleased := changelog.Unreleased()
+	if unreleased == nil {
+		return nil, errors.New("unreleased section not found in CHANGELOG.md")
+	}
+
+	var subsections []string
+	for _, subsection := range unreleased.Subsections {
+		if !isEmptyChangelogBody(strings.Join(subsection.Lines, "")) {
+			subsections = append(subsections, subsection.Name)
 		}
-	}(file)
+	}
+
+	return subsections, nil
+}
+
+// isEmptyChangelogBody returns true if the body only contains whitespace and placeholder dashes, like the "–" put
+// under the unreleased header after a release.
+func isEmptyChangelogBody(body string) bool {
+	return strings.Trim(body, " \t\r\n-–—") == ""
+}
+
+// Update takes everything under the unreleased header (either "Unreleased" or "Development") and puts it under a
+// new header for the version.
+func (c *ChangelogUpdater) Update(newVersion string) error {
+	changelog, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	if err := changelog.AddRelease(newVersion, time.Now()); err != nil {
+		return err
+	}
 
-	if _, err := file.WriteString(changelogContents); err != nil {
+	if err := c.store.WriteFile(c.filePath, []byte(changelog.String())); err != nil {
 		return fmt.Errorf("error writing to CHANGELOG.md: %w", err)
 	}
 
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseChangelogRoundTrip(t *testing.T) {
+	tests := []struct {
+		name         string
+		contents     string
+		wantSections int
+	}{
+		{"empty", "", 0},
+		{"LF", "# Changelog\n\n## Unreleased\n\n- Thing\n\n## v1.0.0 - 1st January 2024\n\n- Other thing\n", 2},
+		{"CRLF", "# Changelog\r\n\r\n## Unreleased\r\n\r\n### Added\r\n\r\n- Thing\r\n\r\n## v1.0.0\r\n", 2},
+		{"no trailing newline", "# Changelog\n\n## Unreleased\n\n- Thing", 1},
+		{"trailing whitespace", "## Unreleased  \n\n- Thing  \n\n\n", 1},
+		{
+			name: "headers in code blocks",
+			contents: "## Unreleased\n\n- Thing\n\n```markdown\n## Not a section\n### Not a subsection\n```\n\n" +
+				"~~~\n## Also not a section\n~~~\n\n## v1.0.0\n",
+			wantSections: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changelog := ParseChangelog(tt.contents)
+
+			if got := changelog.String(); got != tt.contents {
+				t.Errorf("String() = %q, want %q", got, tt.contents)
+			}
+
+			if len(changelog.Sections) != tt.wantSections {
+				t.Errorf("got %d sections, want %d", len(changelog.Sections), tt.wantSections)
+			}
+		})
+	}
+}
+
+func TestParseChangelogSections(t *testing.T) {
+	tests := []struct {
+		header         string
+		wantUnreleased bool
+		wantVersion    string
+		wantDate       string
+	}{
+		{"## Unreleased", true, "", ""},
+		{"## [Unreleased]", true, "", ""},
+		{"## Development", true, "", ""},
+		{"## v1.2.0 - 1st January 2024", false, "1.2.0", "1st January 2024"},
+		{"## v1.2.0", false, "1.2.0", ""},
+		{"## [1.2.0] - 2024-01-01", false, "1.2.0", "2024-01-01"},
+		{"## [1.2.0](https://example.com/compare/v1.1.0...v1.2.0) - 2024-01-01", false, "1.2.0", "2024-01-01"},
+		{"## 1.2.0 (2024-01-01)", false, "1.2.0", "2024-01-01"},
+		{"## v2.0.0-rc.1 — 2024-02-03", false, "2.0.0-rc.1", "2024-02-03"},
+		{"## Notes", false, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			changelog := ParseChangelog(tt.header + "\n\n- Thing\n")
+			if len(changelog.Sections) != 1 {
+				t.Fatalf("got %d sections, want 1", len(changelog.Sections))
+			}
+
+			section := changelog.Sections[0]
+			if section.Unreleased != tt.wantUnreleased || section.Version != tt.wantVersion || section.Date != tt.wantDate {
+				t.Errorf(
+					"got unreleased %t, version %q, date %q, want %t, %q, %q",
+					section.Unreleased,
+					section.Version,
+					section.Date,
+					tt.wantUnreleased,
+					tt.wantVersion,
+					tt.wantDate,
+				)
+			}
+		})
+	}
+}
+
+func TestChangelogAddRelease(t *testing.T) {
+	date := time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		contents string
+		version  string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "default style",
+			contents: "# Changelog\n\n## Unreleased\n\n- New thing\n\n## v1.2.0 - 1st January 2024\n\n- Old thing\n",
+			version:  "v1.3.0",
+			want: "# Changelog\n\n## Unreleased\n\n–\n\n## v1.3.0 - 5th March 2024\n\n- New thing\n\n" +
+				"## v1.2.0 - 1st January 2024\n\n- Old thing\n",
+		},
+		{
+			name: "Keep a Changelog style",
+			contents: "## [Unreleased]\n\n### Added\n\n- New thing\n\n### Fixed\n\n- Bug\n\n" +
+				"## [1.2.0] - 2024-01-01\n\n### Added\n\n- Old thing\n",
+			version: "v1.3.0",
+			want: "## [Unreleased]\n\n–\n\n## [1.3.0] - 2024-03-05\n\n### Added\n\n- New thing\n\n### Fixed\n\n- Bug\n\n" +
+				"## [1.2.0] - 2024-01-01\n\n### Added\n\n- Old thing\n",
+		},
+		{
+			name:     "date in parentheses",
+			contents: "## Development\n\n- New thing\n\n## 1.2.0 (2024-01-01)\n\n- Old thing\n",
+			version:  "v1.3.0",
+			want:     "## Development\n\n–\n\n## 1.3.0 (2024-03-05)\n\n- New thing\n\n## 1.2.0 (2024-01-01)\n\n- Old thing\n",
+		},
+		{
+			name:     "CRLF",
+			contents: "## Unreleased\r\n\r\n- New thing\r\n",
+			version:  "v1.0.0",
+			want:     "## Unreleased\r\n\r\n–\r\n\r\n## v1.0.0 - 5th March 2024\r\n\r\n- New thing\r\n",
+		},
+		{
+			name:     "no trailing newline",
+			contents: "# Changelog\n\n## Unreleased",
+			version:  "v1.0.0",
+			want:     "# Changelog\n\n## Unreleased\n\n–\n\n## v1.0.0 - 5th March 2024\n",
+		},
+		{
+			name:     "no unreleased section",
+			contents: "# Changelog\n\n## v1.2.0\n",
+			version:  "v1.3.0",
+			wantErr:  true,
+		},
+		{
+			name:     "release already exists",
+			contents: "## Unreleased\n\n## [1.3.0] - 2024-01-01\n",
+			version:  "v1.3.0",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changelog := ParseChangelog(tt.contents)
+
+			err := changelog.AddRelease(tt.version, date)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AddRelease() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			if err == nil && changelog.String() != tt.want {
+				t.Errorf("String() = %q, want %q", changelog.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGetVersionNotes(t *testing.T) {
+	projectPath := t.TempDir()
+	contents := "# Changelog\r\n\r\n## [Unreleased]\r\n\r\n## [1.2.0] - 2024-01-01\r\n\r\n### Added\r\n\r\n- Thing\r\n\r\n" +
+		"```\r\n## 1.1.0\r\n```\r\n\r\n\r\n## [1.1.0] - 2023-06-01\r\n\r\n- Other thing\r\n"
+	writeTestFile(t, path.Join(projectPath, "CHANGELOG.md"), contents)
+
+	updater := NewChangelogUpdater(projectPath, &DiskFileStore{})
+
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"v1.2.0", "## [1.2.0] - 2024-01-01\n\n### Added\n\n- Thing\n\n```\n## 1.1.0\n```\n"},
+		{"1.1.0", "## [1.1.0] - 2023-06-01\n\n- Other thing\n"},
+	}
+
+	for _, tt := range tests {
+		got, err := updater.GetVersionNotes(tt.version)
+		if err != nil {
+			t.Fatalf("GetVersionNotes(%s) error = %v", tt.version, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("GetVersionNotes(%s) = %q, want %q", tt.version, got, tt.want)
+		}
+	}
+
+	if _, err := updater.GetVersionNotes("v1.3.0"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("GetVersionNotes(v1.3.0) error = %v, want not found", err)
+	}
+}
@@ -8,19 +8,56 @@ import (
 
 var (
 	rootCmd = &cobra.Command{
-		Use:   "bumper",
+		Use:   "bumper [package path]",
 		Short: "Drew's version bumper",
-		Long:  "Tool for bumping versions using git flow and GitLab / GitHub releases.",
-		Run:   run,
+		Long: "Tool for bumping versions using git flow and GitLab / GitHub releases. In a monorepo, pass the path " +
+			"of the package to bump, e.g. packages/api, to version it separately with tags like packages/api/v1.4.0.",
+		Args: cobra.MaximumNArgs(1),
+		Run:  run,
+	}
+
+	resumeCmd = &cobra.Command{
+		Use:   "resume",
+		Short: "Resume an interrupted version bump",
+		Long:  "Continue a version bump which failed after changes were pushed, e.g. when creating the release failed.",
+		Run:   resume,
+	}
+
+	hotfixCmd = &cobra.Command{
+		Use:   "hotfix",
+		Short: "Release a git flow hotfix",
+		Long:  "Release an urgent patch version from the main branch, without including unreleased changes from dev.",
+	}
+
+	hotfixStartCmd = &cobra.Command{
+		Use:   "start",
+		Short: "Create a hotfix branch for the next patch version",
+		Run:   hotfixStart,
+	}
+
+	hotfixFinishCmd = &cobra.Command{
+		Use:   "finish",
+		Short: "Bump the version on the current hotfix branch and merge it into main and dev",
+		Run:   hotfixFinish,
 	}
 
 	args Args
 )
 
 type Args struct {
-	BumpType string
-	Force    bool
-	Verbose  bool
+	BumpType     string
+	PrereleaseID string
+	Force        bool
+	DryRun       bool
+	Verbose      bool
+
+	MergeRequest bool
+	AutoMerge    bool
+
+	HotfixFrom string
+
+	// The path of the package to bump in a monorepo, from the positional argument.
+	PackagePath string
 }
 
 func ExecuteCmd() error {
@@ -30,12 +67,31 @@ func ExecuteCmd() error {
 func init() {
 	cobra.OnInitialize(Setup)
 
+	rootCmd.AddCommand(resumeCmd)
+	rootCmd.AddCommand(hotfixCmd)
+	hotfixCmd.AddCommand(hotfixStartCmd)
+	hotfixCmd.AddCommand(hotfixFinishCmd)
+
+	hotfixStartCmd.Flags().StringVar(
+		&args.HotfixFrom,
+		"from",
+		"",
+		"tag to start the hotfix from, instead of the main branch [optional]",
+	)
+
 	rootCmd.PersistentFlags().StringVarP(
 		&args.BumpType,
 		"type",
 		"t",
 		"",
-		"type of version bump (major, minor, patch) [optional]",
+		"type of version bump (major, minor, patch, premajor, preminor, prepatch, prerelease, release, auto) [optional]",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&args.PrereleaseID,
+		"preid",
+		"",
+		"identifier to use for pre-release versions, e.g. alpha, beta or rc (default rc) [optional]",
 	)
 
 	rootCmd.PersistentFlags().BoolVarP(
@@ -46,6 +102,27 @@ func init() {
 		"run without confirmation [optional]",
 	)
 
+	rootCmd.PersistentFlags().BoolVar(
+		&args.DryRun,
+		"dry-run",
+		false,
+		"show what would be changed without making any changes [optional]",
+	)
+
+	rootCmd.PersistentFlags().BoolVar(
+		&args.MergeRequest,
+		"merge-request",
+		false,
+		"open a merge request / pull request instead of merging the release branch locally [optional]",
+	)
+
+	rootCmd.PersistentFlags().BoolVar(
+		&args.AutoMerge,
+		"auto-merge",
+		false,
+		"enable auto-merge on the merge request / pull request [optional]",
+	)
+
 	rootCmd.PersistentFlags().BoolVarP(
 		&args.Verbose,
 		"verbose",
@@ -55,7 +132,11 @@ func init() {
 	)
 }
 
-func run(_ *cobra.Command, _ []string) {
+func run(_ *cobra.Command, positionalArgs []string) {
+	if len(positionalArgs) > 0 {
+		args.PackagePath = positionalArgs[0]
+	}
+
 	conf := NewConfig(args)
 
 	zerolog.SetGlobalLevel(conf.LogLevel)
@@ -65,3 +146,36 @@ func run(_ *cobra.Command, _ []string) {
 		log.Fatal().Msgf("Failed to bump version: %v", err)
 	}
 }
+
+func resume(_ *cobra.Command, _ []string) {
+	conf := NewConfig(args)
+
+	zerolog.SetGlobalLevel(conf.LogLevel)
+
+	bumper := Bumper{conf: conf}
+	if err := bumper.Resume(); err != nil {
+		log.Fatal().Msgf("Failed to resume version bump: %v", err)
+	}
+}
+
+func hotfixStart(_ *cobra.Command, _ []string) {
+	conf := NewConfig(args)
+
+	zerolog.SetGlobalLevel(conf.LogLevel)
+
+	bumper := Bumper{conf: conf}
+	if err := bumper.StartHotfix(args.HotfixFrom); err != nil {
+		log.Fatal().Msgf("Failed to start hotfix: %v", err)
+	}
+}
+
+func hotfixFinish(_ *cobra.Command, _ []string) {
+	conf := NewConfig(args)
+
+	zerolog.SetGlobalLevel(conf.LogLevel)
+
+	bumper := Bumper{conf: conf}
+	if err := bumper.FinishHotfix(); err != nil {
+		log.Fatal().Msgf("Failed to finish hotfix: %v", err)
+	}
+}
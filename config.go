@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"regexp"
 	"strings"
 
 	"github.com/rs/zerolog"
@@ -15,10 +16,15 @@ import (
 const (
 	configName = "config"
 	configType = "toml"
+
+	// Project config lives in the project root and overrides the user config, apart from API keys.
+	projectConfigName = ".bumper.toml"
 )
 
 var configPath string
 
+var projectViper = viper.New()
+
 func Setup() {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -38,45 +44,184 @@ func Setup() {
 			log.Fatal().Msgf("Error reading config file: %v", err)
 		}
 	}
+
+	if fileExists(projectConfigName) {
+		projectViper.SetConfigFile(projectConfigName)
+		projectViper.SetConfigType(configType)
+
+		if err := projectViper.ReadInConfig(); err != nil {
+			log.Fatal().Msgf("Error reading project config file: %v", err)
+		}
+	}
+}
+
+// projectString returns the config value from the project config if set, otherwise from the user config, otherwise
+// the default value.
+func projectString(key string, defaultValue string) string {
+	if projectViper.IsSet(key) {
+		return projectViper.GetString(key)
+	}
+
+	if viper.IsSet(key) {
+		return viper.GetString(key)
+	}
+
+	return defaultValue
+}
+
+// projectBool is the same as projectString but for boolean values.
+func projectBool(key string, defaultValue bool) bool {
+	if projectViper.IsSet(key) {
+		return projectViper.GetBool(key)
+	}
+
+	if viper.IsSet(key) {
+		return viper.GetBool(key)
+	}
+
+	return defaultValue
 }
 
 type Config struct {
-	// In the future, we could add more config options here, like branch names, etc.
 	GitlabAPIKey string
+	GithubAPIKey string
+	GiteaAPIKey  string
 	BumpType     *BumpType
-	Force        bool
-	LogLevel     zerolog.Level
+	PrereleaseID string
+
+	// Suggest the bump type in the prompt based on the Keep a Changelog sections with unreleased changes.
+	InferBumpFromChangelog bool
+
+	// Commit the next development version (e.g. a Maven -SNAPSHOT version) to the development branch after
+	// releasing, for packagers which support it.
+	NextDevelopmentVersion bool
+
+	// Increment the Android versionCode along with versionName when bumping Gradle projects.
+	AndroidVersionCode bool
+
+	// Keep appVersion in sync with the chart version when bumping Helm charts.
+	HelmAppVersion bool
+
+	// Other files which contain the version, from the project config.
+	VersionFiles []VersionFile
+
+	// The package to bump in a monorepo, relative to the repository root. Empty if the whole repository has a
+	// single version.
+	PackagePath string
+
+	Force    bool
+	DryRun   bool
+	LogLevel zerolog.Level
+
+	BranchingModel      BranchingModelType
+	MainBranch          string
+	DevBranch           string
+	ReleaseBranchPrefix string
+	HotfixBranchPrefix  string
+
+	// Open a merge request for the release branch instead of merging it locally, for protected branches.
+	MergeRequest bool
+	AutoMerge    bool
 }
 
 func NewConfig(args Args) *Config {
 	conf := Config{}
 
 	conf.GitlabAPIKey = viper.GetString("gitlab_api_key")
+	conf.GithubAPIKey = viper.GetString("github_api_key")
+	conf.GiteaAPIKey = viper.GetString("gitea_api_key")
 	conf.Force = args.Force
+	conf.DryRun = args.DryRun
+	conf.PackagePath = args.PackagePath
+
+	conf.BranchingModel = BranchingModelType(strings.ToLower(projectString("branching_model", string(BranchingModelGitFlow))))
+	switch conf.BranchingModel {
+	case BranchingModelGitFlow, BranchingModelTrunk, BranchingModelReleaseBranch:
+	default:
+		log.Fatal().Msgf("Invalid branching model: %s", conf.BranchingModel)
+	}
+
+	conf.MainBranch = projectString("main_branch", "main")
+	conf.DevBranch = projectString("dev_branch", "dev")
+	conf.ReleaseBranchPrefix = projectString("release_branch_prefix", "release/")
+	conf.HotfixBranchPrefix = projectString("hotfix_branch_prefix", "hotfix/")
+	conf.MergeRequest = args.MergeRequest || projectBool("merge_request", false)
+	conf.AutoMerge = args.AutoMerge || projectBool("auto_merge", false)
 
 	conf.LogLevel = zerolog.InfoLevel
 	if args.Verbose {
 		conf.LogLevel = zerolog.DebugLevel
 	}
 
-	switch strings.ToLower(args.BumpType) {
-	case "major":
-		conf.BumpType = Ptr(BumpTypeMajor)
-	case "minor":
-		conf.BumpType = Ptr(BumpTypeMinor)
-	case "patch":
-		conf.BumpType = Ptr(BumpTypePatch)
-	case "":
-		conf.BumpType = nil
-	default:
-		log.Fatal().Msgf("Invalid bump type: %s", args.BumpType)
+	if args.BumpType != "" {
+		bumpType, err := ParseBumpType(args.BumpType)
+		if err != nil {
+			log.Fatal().Msgf("Invalid bump type: %s", args.BumpType)
+		}
+
+		conf.BumpType = &bumpType
+	}
+
+	conf.InferBumpFromChangelog = projectBool("infer_bump_from_changelog", false)
+	conf.NextDevelopmentVersion = projectBool("next_development_version", false)
+	conf.AndroidVersionCode = projectBool("android_version_code", false)
+	conf.HelmAppVersion = projectBool("helm_app_version", false)
+	conf.VersionFiles = readVersionFiles()
+
+	conf.PrereleaseID = args.PrereleaseID
+	if conf.PrereleaseID == "" {
+		conf.PrereleaseID = projectString("prerelease_id", "rc")
 	}
 
 	return &conf
 }
 
+// VersionFile is a set of files containing the version, matched by a glob relative to the project root. The
+// version is found using a regex, from either the group named "version" or the first group.
+type VersionFile struct {
+	Glob    string
+	Pattern *regexp.Regexp
+}
+
+type versionFileConfig struct {
+	Glob    string `mapstructure:"glob"`
+	Pattern string `mapstructure:"pattern"`
+}
+
+// readVersionFiles reads the [[version_files]] from the project config. These only make sense per-project, so the
+// user config isn't used.
+func readVersionFiles() []VersionFile {
+	var versionFileConfigs []versionFileConfig
+	if err := projectViper.UnmarshalKey("version_files", &versionFileConfigs); err != nil {
+		log.Fatal().Msgf("Invalid version_files config: %v", err)
+	}
+
+	versionFiles := make([]VersionFile, 0, len(versionFileConfigs))
+	for _, versionFileConfig := range versionFileConfigs {
+		if versionFileConfig.Glob == "" || versionFileConfig.Pattern == "" {
+			log.Fatal().Msg("Invalid version_files config: glob and pattern are both required")
+		}
+
+		// Multi-line mode so that ^ and $ match the start and end of lines.
+		pattern, err := regexp.Compile("(?m)" + versionFileConfig.Pattern)
+		if err != nil {
+			log.Fatal().Msgf("Invalid version_files pattern %s: %v", versionFileConfig.Pattern, err)
+		}
+
+		if pattern.NumSubexp() == 0 {
+			log.Fatal().Msgf("Invalid version_files pattern %s: no version capture group", versionFileConfig.Pattern)
+		}
+
+		versionFiles = append(versionFiles, VersionFile{Glob: versionFileConfig.Glob, Pattern: pattern})
+	}
+
+	return versionFiles
+}
+
 func (c *Config) Write() error {
 	viper.Set("gitlab_api_key", c.GitlabAPIKey)
+	viper.Set("github_api_key", c.GithubAPIKey)
+	viper.Set("gitea_api_key", c.GiteaAPIKey)
 	viper.ConfigFileUsed()
 
 	// If it already exists, that's fine.
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Matches the header of a conventional commit, e.g. "feat(api)!: remove v1 endpoints".
+var conventionalCommitRe = regexp.MustCompile(`^(\w+)(?:\([^)]*\))?(!)?: .+`)
+var breakingChangeFooterRe = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+
+// BumpReason is a commit which contributed to the inferred bump type.
+type BumpReason struct {
+	BumpType BumpType
+	Commit   Commit
+}
+
+func (r BumpReason) String() string {
+	header, _, _ := strings.Cut(r.Commit.Message, "\n")
+	return fmt.Sprintf("%s %s", r.Commit.Hash[:min(len(r.Commit.Hash), 8)], header)
+}
+
+// inferBumpTypeFromCommits works out the bump type from Conventional Commits messages: breaking changes give a
+// major bump, features give a minor bump and fixes give a patch bump. Commits which don't follow the convention or
+// have other types (docs, chore, etc.) are ignored. The reasons are the commits which triggered the chosen bump.
+func inferBumpTypeFromCommits(commits []Commit) (BumpType, []BumpReason, error) {
+	reasonsByType := make(map[BumpType][]BumpReason)
+
+	for _, commit := range commits {
+		bumpType, ok := conventionalCommitBumpType(commit.Message)
+		if !ok {
+			continue
+		}
+
+		reasonsByType[bumpType] = append(reasonsByType[bumpType], BumpReason{BumpType: bumpType, Commit: commit})
+	}
+
+	for _, bumpType := range []BumpType{BumpTypeMajor, BumpTypeMinor, BumpTypePatch} {
+		if reasons, ok := reasonsByType[bumpType]; ok {
+			return bumpType, reasons, nil
+		}
+	}
+
+	return 0, nil, fmt.Errorf(
+		"none of the %d commit(s) are breaking changes, features or fixes - specify the bump type explicitly",
+		len(commits),
+	)
+}
+
+func conventionalCommitBumpType(message string) (BumpType, bool) {
+	matches := conventionalCommitRe.FindStringSubmatch(message)
+	if matches == nil {
+		return 0, false
+	}
+
+	if matches[2] == "!" || breakingChangeFooterRe.MatchString(message) {
+		return BumpTypeMajor, true
+	}
+
+	switch strings.ToLower(matches[1]) {
+	case "feat":
+		return BumpTypeMinor, true
+	case "fix":
+		return BumpTypePatch, true
+	default:
+		return 0, false
+	}
+}
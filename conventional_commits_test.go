@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver"
+)
+
+func TestConventionalCommitBumpType(t *testing.T) {
+	tests := []struct {
+		message  string
+		want     BumpType
+		wantBump bool
+	}{
+		{"feat: add thing", BumpTypeMinor, true},
+		{"feat(api): add thing", BumpTypeMinor, true},
+		{"Feat: add thing", BumpTypeMinor, true},
+		{"fix: correct thing", BumpTypePatch, true},
+		{"fix(parser): correct thing\n\nLonger description.", BumpTypePatch, true},
+		{"feat!: remove thing", BumpTypeMajor, true},
+		{"refactor(api)!: rename thing", BumpTypeMajor, true},
+		{"feat: change thing\n\nBREAKING CHANGE: thing works differently", BumpTypeMajor, true},
+		{"fix: change thing\n\nBREAKING-CHANGE: thing works differently", BumpTypeMajor, true},
+		{"docs: update readme", 0, false},
+		{"chore(deps): bump dependency", 0, false},
+		{"Add thing", 0, false},
+		{"feat:missing space", 0, false},
+		{"fix: mention BREAKING CHANGE: in the header only", BumpTypePatch, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := conventionalCommitBumpType(tt.message)
+		if ok != tt.wantBump || got != tt.want {
+			t.Errorf("conventionalCommitBumpType(%q) = %s, %t, want %s, %t", tt.message, got, ok, tt.want, tt.wantBump)
+		}
+	}
+}
+
+func TestInferBumpTypeFromCommits(t *testing.T) {
+	commits := []Commit{
+		{Hash: "aaaaaaaaaaaa", Message: "docs: update readme"},
+		{Hash: "bbbbbbbbbbbb", Message: "fix: correct thing"},
+		{Hash: "cccccccccccc", Message: "feat: add thing"},
+		{Hash: "dddddddddddd", Message: "feat(api): add other thing"},
+	}
+
+	bumpType, reasons, err := inferBumpTypeFromCommits(commits)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if bumpType != BumpTypeMinor {
+		t.Errorf("bump type = %s, want minor", bumpType)
+	}
+
+	if len(reasons) != 2 || reasons[0].String() != "cccccccc feat: add thing" {
+		t.Errorf("reasons = %v, want the two feat commits", reasons)
+	}
+
+	if _, _, err := inferBumpTypeFromCommits([]Commit{{Hash: "aaaa", Message: "chore: tidy up"}}); err == nil {
+		t.Error("expected error when no commits trigger a bump")
+	}
+}
+
+// Inferred bumps of a pre-release should release it rather than skipping over its final version.
+func TestInferredBumpOfPrerelease(t *testing.T) {
+	tests := []struct {
+		version string
+		message string
+		want    string
+	}{
+		{"2.0.0-rc.1", "feat: add thing", "2.0.0"},
+		{"2.0.0-rc.1", "fix: correct thing", "2.0.0"},
+		{"2.0.0-rc.1", "feat!: remove thing", "2.0.0"},
+		{"2.1.0-rc.1", "fix: correct thing", "2.1.0"},
+		{"2.1.0-rc.1", "feat!: remove thing", "3.0.0"},
+	}
+
+	for _, tt := range tests {
+		bumpType, _, err := inferBumpTypeFromCommits([]Commit{{Hash: "abcdef", Message: tt.message}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got, err := bumpType.NextVersion(semver.MustParse(tt.version), "rc")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got.String() != tt.want {
+			t.Errorf("%q on %s = %s, want %s", tt.message, tt.version, got.String(), tt.want)
+		}
+	}
+}
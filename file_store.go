@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+// FileStore is used by the packagers and changelog updater to read and write project files, so that a dry run
+// can show what would be changed without actually touching the working tree.
+type FileStore interface {
+	ReadFile(filePath string) ([]byte, error)
+	WriteFile(filePath string, contents []byte) error
+}
+
+// DiskFileStore reads and writes files directly on disk.
+type DiskFileStore struct{}
+
+func (s *DiskFileStore) ReadFile(filePath string) ([]byte, error) {
+	return os.ReadFile(filePath)
+}
+
+func (s *DiskFileStore) WriteFile(filePath string, contents []byte) error {
+	// Keep the existing permissions if the file is already there.
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(filePath); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	return os.WriteFile(filePath, contents, perm)
+}
+
+// DryRunFileStore reads files from disk but keeps any writes in memory. Subsequent reads of a written file see
+// the written contents, so later steps behave as they would in a real run.
+type DryRunFileStore struct {
+	original map[string][]byte
+	written  map[string][]byte
+}
+
+func NewDryRunFileStore() *DryRunFileStore {
+	return &DryRunFileStore{
+		original: make(map[string][]byte),
+		written:  make(map[string][]byte),
+	}
+}
+
+func (s *DryRunFileStore) ReadFile(filePath string) ([]byte, error) {
+	if contents, ok := s.written[filePath]; ok {
+		return bytes.Clone(contents), nil
+	}
+
+	return os.ReadFile(filePath)
+}
+
+func (s *DryRunFileStore) WriteFile(filePath string, contents []byte) error {
+	if _, ok := s.original[filePath]; !ok {
+		original, err := os.ReadFile(filePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error reading %s: %w", filePath, err)
+		}
+
+		s.original[filePath] = original
+	}
+
+	s.written[filePath] = bytes.Clone(contents)
+	return nil
+}
+
+// Diff returns a unified diff of all the changes which would have been written, relative to the project path.
+func (s *DryRunFileStore) Diff(projectPath string) (string, error) {
+	tempDir, err := os.MkdirTemp("", "bumper-dry-run-")
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+
+	filePaths := make([]string, 0, len(s.written))
+	for filePath := range s.written {
+		filePaths = append(filePaths, filePath)
+	}
+	sort.Strings(filePaths)
+
+	var diff bytes.Buffer
+	for _, filePath := range filePaths {
+		relPath, err := filepath.Rel(projectPath, filePath)
+		if err != nil {
+			relPath = filepath.Base(filePath)
+		}
+
+		// Lay the files out as a/<path> and b/<path> so that git's diff headers look like a normal diff.
+		for prefix, contents := range map[string][]byte{"a": s.original[filePath], "b": s.written[filePath]} {
+			tempPath := filepath.Join(tempDir, prefix, relPath)
+			if err := os.MkdirAll(filepath.Dir(tempPath), 0700); err != nil {
+				return "", fmt.Errorf("error creating temporary directory: %w", err)
+			}
+			if err := os.WriteFile(tempPath, contents, 0600); err != nil {
+				return "", fmt.Errorf("error writing temporary file: %w", err)
+			}
+		}
+
+		gitDiff := exec.Command(
+			"git", "diff", "--no-index", "--no-prefix",
+			filepath.Join("a", relPath), filepath.Join("b", relPath),
+		)
+		gitDiff.Dir = tempDir
+		output, err := gitDiff.Output()
+
+		// git diff exits with 1 when there are differences.
+		var exitErr *exec.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+			return "", fmt.Errorf("error diffing %s: %w", relPath, err)
+		}
+
+		diff.Write(output)
+	}
+
+	return diff.String(), nil
+}
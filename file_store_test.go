@@ -0,0 +1,110 @@
+package main
+
+import (
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestDryRunFileStoreReadAfterWrite(t *testing.T) {
+	projectPath := t.TempDir()
+	versionFilePath := path.Join(projectPath, "VERSION")
+	otherFilePath := path.Join(projectPath, "other.txt")
+	newFilePath := path.Join(projectPath, "new.txt")
+	writeTestFile(t, versionFilePath, "1.2.3\n")
+	writeTestFile(t, otherFilePath, "unchanged\n")
+
+	store := NewDryRunFileStore()
+
+	if err := store.WriteFile(versionFilePath, []byte("1.3.0\n")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	contents, err := store.ReadFile(versionFilePath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	if string(contents) != "1.3.0\n" {
+		t.Errorf("ReadFile() = %q, want the written contents", contents)
+	}
+
+	// Changing the returned bytes mustn't change what's stored.
+	contents[0] = '9'
+	if contents, _ := store.ReadFile(versionFilePath); string(contents) != "1.3.0\n" {
+		t.Errorf("ReadFile() after modifying previous result = %q, want 1.3.0", contents)
+	}
+
+	if contents, err := store.ReadFile(otherFilePath); err != nil || string(contents) != "unchanged\n" {
+		t.Errorf("ReadFile() of unwritten file = %q, %v, want contents from disk", contents, err)
+	}
+
+	if err := store.WriteFile(newFilePath, []byte("new\n")); err != nil {
+		t.Fatalf("WriteFile() of new file error = %v", err)
+	}
+
+	if contents, err := store.ReadFile(newFilePath); err != nil || string(contents) != "new\n" {
+		t.Errorf("ReadFile() of new file = %q, %v, want the written contents", contents, err)
+	}
+
+	// Nothing should have been written to disk.
+	assertFileContents(t, versionFilePath, "1.2.3\n")
+	if fileExists(newFilePath) {
+		t.Error("new file was written to disk")
+	}
+}
+
+func TestDryRunFileStoreDiff(t *testing.T) {
+	projectPath := t.TempDir()
+	writeTestFile(t, path.Join(projectPath, "sub/VERSION"), "1.2.3\n")
+	writeTestFile(t, path.Join(projectPath, "CHANGELOG.md"), "# Changelog\n\n## Unreleased\n")
+	writeTestFile(t, path.Join(projectPath, "same.txt"), "same\n")
+
+	store := NewDryRunFileStore()
+	writes := []struct {
+		filePath string
+		contents string
+	}{
+		{"sub/VERSION", "1.2.4\n"},
+		{"sub/VERSION", "1.3.0\n"},
+		{"CHANGELOG.md", "# Changelog\n\n## Unreleased\n\n## v1.3.0\n"},
+		{"same.txt", "same\n"},
+	}
+	for _, write := range writes {
+		if err := store.WriteFile(path.Join(projectPath, write.filePath), []byte(write.contents)); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	diff, err := store.Diff(projectPath)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+
+	wantLines := []string{
+		"--- a/CHANGELOG.md",
+		"+++ b/CHANGELOG.md",
+		"+## v1.3.0",
+		"--- a/sub/VERSION",
+		"+++ b/sub/VERSION",
+		"-1.2.3",
+		"+1.3.0",
+	}
+
+	// The files are diffed in order, against the contents from before the first write.
+	lastIndex := -1
+	for _, wantLine := range wantLines {
+		index := strings.Index(diff, "\n"+wantLine+"\n")
+		if index <= lastIndex {
+			t.Errorf("Diff() = %s\nwant %q after the previous line", diff, wantLine)
+		}
+
+		lastIndex = index
+	}
+
+	for _, unwantedLine := range []string{"+1.2.4", "same.txt"} {
+		if strings.Contains(diff, unwantedLine) {
+			t.Errorf("Diff() = %s\nwant no %q", diff, unwantedLine)
+		}
+	}
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
+
+	"github.com/rs/zerolog/log"
 )
 
-type GitWrapper struct{}
+type GitWrapper struct {
+	// DryRun logs any commands which would modify the repository or remote instead of running them.
+	DryRun bool
+}
 
 func (g *GitWrapper) GetCurrentBranch() (string, error) {
 	getCurrentBranch := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
@@ -20,8 +26,22 @@ func (g *GitWrapper) GetCurrentBranch() (string, error) {
 	return currentBranch, nil
 }
 
-func (g *GitWrapper) GetLatestTag() (string, error) {
-	getLatestTag := exec.Command("git", "describe", "--tags", "--abbrev=0")
+// GetLatestTag returns the most recent tag reachable from HEAD. If a prefix is given, e.g. "packages/api/" for a
+// package in a monorepo, only tags with that prefix followed by a version are considered. Otherwise, package tags are
+// ignored.
+func (g *GitWrapper) GetLatestTag(prefix string) (string, error) {
+	return g.GetLatestTagFrom("HEAD", prefix)
+}
+
+// GetLatestTagFrom is the same as GetLatestTag, but for the most recent tag reachable from the given ref.
+func (g *GitWrapper) GetLatestTagFrom(ref string, prefix string) (string, error) {
+	// Exclude the tags of any packages nested below the prefix, e.g. packages/api/client/v1.0.0 for packages/api/.
+	describeArgs := []string{"describe", "--tags", "--abbrev=0", "--exclude", prefix + "*/*"}
+	if prefix != "" {
+		describeArgs = append(describeArgs, "--match", prefix+"v[0-9]*")
+	}
+
+	getLatestTag := exec.Command("git", append(describeArgs, ref)...)
 	output, err := getLatestTag.Output()
 	if err != nil {
 		return "", fmt.Errorf("error getting latest tag: %w", err)
@@ -33,7 +53,7 @@ func (g *GitWrapper) GetLatestTag() (string, error) {
 
 func (g *GitWrapper) CreateBranch(branchName string) error {
 	createBranch := exec.Command("git", "checkout", "-b", branchName)
-	if err := createBranch.Run(); err != nil {
+	if err := g.run(createBranch); err != nil {
 		return fmt.Errorf("error creating branch: %w", err)
 	}
 
@@ -42,7 +62,7 @@ func (g *GitWrapper) CreateBranch(branchName string) error {
 
 func (g *GitWrapper) CheckoutBranch(branchName string) error {
 	checkoutBranch := exec.Command("git", "checkout", branchName)
-	if err := checkoutBranch.Run(); err != nil {
+	if err := g.run(checkoutBranch); err != nil {
 		return fmt.Errorf("error checking out branch: %w", err)
 	}
 
@@ -51,7 +71,7 @@ func (g *GitWrapper) CheckoutBranch(branchName string) error {
 
 func (g *GitWrapper) MergeBranch(branchName string) error {
 	mergeBranch := exec.Command("git", "merge", branchName, "--no-ff")
-	if err := mergeBranch.Run(); err != nil {
+	if err := g.run(mergeBranch); err != nil {
 		return fmt.Errorf("error merging branch: %w", err)
 	}
 
@@ -60,16 +80,25 @@ func (g *GitWrapper) MergeBranch(branchName string) error {
 
 func (g *GitWrapper) Tag(tagName string) error {
 	tag := exec.Command("git", "tag", tagName)
-	if err := tag.Run(); err != nil {
+	if err := g.run(tag); err != nil {
 		return fmt.Errorf("error tagging: %w", err)
 	}
 
 	return nil
 }
 
+func (g *GitWrapper) TagCommit(tagName string, commitHash string) error {
+	tag := exec.Command("git", "tag", tagName, commitHash)
+	if err := g.run(tag); err != nil {
+		return fmt.Errorf("error tagging %s: %w", commitHash, err)
+	}
+
+	return nil
+}
+
 func (g *GitWrapper) Push() error {
 	push := exec.Command("git", "push")
-	if err := push.Run(); err != nil {
+	if err := g.run(push); err != nil {
 		return fmt.Errorf("error pushing: %w", err)
 	}
 
@@ -78,7 +107,7 @@ func (g *GitWrapper) Push() error {
 
 func (g *GitWrapper) PushTags() error {
 	pushTags := exec.Command("git", "push", "--tags")
-	if err := pushTags.Run(); err != nil {
+	if err := g.run(pushTags); err != nil {
 		return fmt.Errorf("error pushing tags: %w", err)
 	}
 
@@ -87,7 +116,7 @@ func (g *GitWrapper) PushTags() error {
 
 func (g *GitWrapper) DeleteBranch(branchName string) error {
 	deleteBranch := exec.Command("git", "branch", "-d", branchName)
-	if err := deleteBranch.Run(); err != nil {
+	if err := g.run(deleteBranch); err != nil {
 		return fmt.Errorf("error deleting branch: %w", err)
 	}
 
@@ -96,7 +125,7 @@ func (g *GitWrapper) DeleteBranch(branchName string) error {
 
 func (g *GitWrapper) Commit(message string) error {
 	commit := exec.Command("git", "commit", "-am", message)
-	if err := commit.Run(); err != nil {
+	if err := g.run(commit); err != nil {
 		return fmt.Errorf("error committing: %w", err)
 	}
 
@@ -105,13 +134,28 @@ func (g *GitWrapper) Commit(message string) error {
 
 func (g *GitWrapper) Add(path string) error {
 	add := exec.Command("git", "add", path)
-	if err := add.Run(); err != nil {
+	if err := g.run(add); err != nil {
 		return fmt.Errorf("error adding: %w", err)
 	}
 
 	return nil
 }
 
+// IsIgnored returns true if the path is ignored by git and isn't already tracked.
+func (g *GitWrapper) IsIgnored(path string) (bool, error) {
+	checkIgnore := exec.Command("git", "check-ignore", "--quiet", path)
+	if err := checkIgnore.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("error checking if %s is ignored: %w", path, err)
+	}
+
+	return true, nil
+}
+
 func (g *GitWrapper) HasUncommittedChanges() (bool, error) {
 	status := exec.Command("git", "status", "--porcelain")
 	output, err := status.Output()
@@ -124,12 +168,12 @@ func (g *GitWrapper) HasUncommittedChanges() (bool, error) {
 
 func (g *GitWrapper) RevertChanges() error {
 	restoreStaged := exec.Command("git", "restore", "--staged", ".")
-	if err := restoreStaged.Run(); err != nil {
+	if err := g.run(restoreStaged); err != nil {
 		return fmt.Errorf("error restoring staged changes: %w", err)
 	}
 
 	restore := exec.Command("git", "restore", ".")
-	if err := restore.Run(); err != nil {
+	if err := g.run(restore); err != nil {
 		return fmt.Errorf("error restoring changes: %w", err)
 	}
 
@@ -157,3 +201,141 @@ func (g *GitWrapper) GetOriginRemoteURL() (string, error) {
 	remoteURL := strings.TrimSpace(string(output))
 	return remoteURL, nil
 }
+
+// run runs a command which modifies the repository or remote, unless this is a dry run.
+func (g *GitWrapper) run(cmd *exec.Cmd) error {
+	if g.DryRun {
+		log.Info().Msgf("Dry run: would run `%s`", strings.Join(cmd.Args, " "))
+		return nil
+	}
+
+	return cmd.Run()
+}
+
+func (g *GitWrapper) GetCommitHash(ref string) (string, error) {
+	revParse := exec.Command("git", "rev-parse", "--verify", ref)
+	output, err := revParse.Output()
+	if err != nil {
+		return "", fmt.Errorf("error getting commit hash for %s: %w", ref, err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// IsAncestor returns true if the commit is reachable from the ref.
+func (g *GitWrapper) IsAncestor(commitHash string, ref string) bool {
+	mergeBase := exec.Command("git", "merge-base", "--is-ancestor", commitHash, ref)
+	return mergeBase.Run() == nil
+}
+
+func (g *GitWrapper) BranchExists(branchName string) bool {
+	showRef := exec.Command("git", "show-ref", "--verify", "--quiet", fmt.Sprintf("refs/heads/%s", branchName))
+	return showRef.Run() == nil
+}
+
+func (g *GitWrapper) CreateBranchAt(branchName string, commitHash string) error {
+	createBranch := exec.Command("git", "branch", branchName, commitHash)
+	if err := g.run(createBranch); err != nil {
+		return fmt.Errorf("error creating branch: %w", err)
+	}
+
+	return nil
+}
+
+func (g *GitWrapper) ForceDeleteBranch(branchName string) error {
+	deleteBranch := exec.Command("git", "branch", "-D", branchName)
+	if err := g.run(deleteBranch); err != nil {
+		return fmt.Errorf("error deleting branch: %w", err)
+	}
+
+	return nil
+}
+
+func (g *GitWrapper) DeleteTag(tagName string) error {
+	deleteTag := exec.Command("git", "tag", "-d", tagName)
+	if err := g.run(deleteTag); err != nil {
+		return fmt.Errorf("error deleting tag: %w", err)
+	}
+
+	return nil
+}
+
+func (g *GitWrapper) ResetHard(ref string) error {
+	reset := exec.Command("git", "reset", "--hard", ref)
+	if err := g.run(reset); err != nil {
+		return fmt.Errorf("error resetting to %s: %w", ref, err)
+	}
+
+	return nil
+}
+
+func (g *GitWrapper) GetGitDir() (string, error) {
+	getGitDir := exec.Command("git", "rev-parse", "--absolute-git-dir")
+	output, err := getGitDir.Output()
+	if err != nil {
+		return "", fmt.Errorf("error getting git directory: %w", err)
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (g *GitWrapper) PushBranch(branchName string) error {
+	push := exec.Command("git", "push", "--set-upstream", "origin", branchName)
+	if err := g.run(push); err != nil {
+		return fmt.Errorf("error pushing branch: %w", err)
+	}
+
+	return nil
+}
+
+func (g *GitWrapper) CreateBranchFrom(branchName string, startPoint string) error {
+	createBranch := exec.Command("git", "checkout", "-b", branchName, startPoint)
+	if err := g.run(createBranch); err != nil {
+		return fmt.Errorf("error creating branch: %w", err)
+	}
+
+	return nil
+}
+
+func (g *GitWrapper) Pull(branchName string) error {
+	pull := exec.Command("git", "pull", "--ff-only", "origin", branchName)
+	if err := g.run(pull); err != nil {
+		return fmt.Errorf("error pulling: %w", err)
+	}
+
+	return nil
+}
+
+// Commit is a commit hash and its full commit message.
+type Commit struct {
+	Hash    string
+	Message string
+}
+
+// GetCommitsSince returns the commits which are reachable from HEAD but not from the given ref, newest first. If any
+// paths are given, only commits which touch those paths are returned.
+func (g *GitWrapper) GetCommitsSince(ref string, paths ...string) ([]Commit, error) {
+	// Separate fields with NUL and records with the ASCII record separator, as neither appear in commit messages.
+	logArgs := []string{"log", "--format=%H%x00%B%x1e", fmt.Sprintf("%s..HEAD", ref)}
+	if len(paths) > 0 {
+		logArgs = append(append(logArgs, "--"), paths...)
+	}
+
+	gitLog := exec.Command("git", logArgs...)
+	output, err := gitLog.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error getting commits since %s: %w", ref, err)
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(string(output), "\x1e") {
+		hash, message, found := strings.Cut(strings.TrimSpace(record), "\x00")
+		if !found {
+			continue
+		}
+
+		commits = append(commits, Commit{Hash: hash, Message: strings.TrimSpace(message)})
+	}
+
+	return commits, nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/rs/zerolog/log"
+)
+
+// HotfixModel is used to finish a git flow hotfix. The hotfix branch has already been created from main and
+// contains the fix, so it just needs the version bump committing on it before it's merged into main and back into
+// dev, in exactly the same way as a git flow release branch.
+//
+// Hotfixes of older releases are maintenance hotfixes, which are tagged on the hotfix branch and pushed without
+// being merged, as main and dev have already moved on to later versions.
+type HotfixModel struct {
+	GitFlowModel
+	hotfixBranch string
+	maintenance  bool
+}
+
+func newHotfixModel(conf *Config, hotfixBranch string, maintenance bool) *HotfixModel {
+	return &HotfixModel{
+		GitFlowModel: GitFlowModel{
+			mainBranch:   conf.MainBranch,
+			devBranch:    conf.DevBranch,
+			mergeRequest: conf.MergeRequest,
+		},
+		hotfixBranch: hotfixBranch,
+		maintenance:  maintenance,
+	}
+}
+
+func (m *HotfixModel) Name() string {
+	return "git-flow hotfix"
+}
+
+func (m *HotfixModel) StartBranch() string {
+	return m.hotfixBranch
+}
+
+func (m *HotfixModel) ReleaseBranch(_ string) string {
+	return m.hotfixBranch
+}
+
+func (m *HotfixModel) PrepareSteps(_ *bumpContext) []BumpStep {
+	return nil
+}
+
+func (m *HotfixModel) PublishSteps(c *bumpContext) []BumpStep {
+	if !m.maintenance {
+		return m.GitFlowModel.PublishSteps(c)
+	}
+
+	return []BumpStep{
+		{
+			Name: "tag release",
+			Run:  c.tagRelease,
+			Undo: c.undoTagRelease,
+		},
+		{
+			Name:      "push hotfix branch",
+			Run:       func() error { return c.git.PushBranch(m.hotfixBranch) },
+			Published: true,
+		},
+		{
+			Name:      "push tags",
+			Run:       c.git.PushTags,
+			Published: true,
+		},
+	}
+}
+
+// StartHotfix creates a hotfix branch for the next patch version, from either the main branch or the given tag.
+func (b *Bumper) StartHotfix(fromTag string) error {
+	if b.conf.BranchingModel != BranchingModelGitFlow {
+		return fmt.Errorf("hotfixes are only supported with the git-flow branching model, not %s", b.conf.BranchingModel)
+	}
+
+	git := GitWrapper{DryRun: b.conf.DryRun}
+
+	if _, err := checkNoIncompleteBump(&git); err != nil {
+		return err
+	}
+
+	if hasChanges, err := git.HasUncommittedChanges(); err != nil {
+		return fmt.Errorf("error checking for uncommitted changes: %w", err)
+	} else if hasChanges {
+		return errors.New("uncommitted changes found - commit / stash changes before starting hotfix")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("error getting current working directory: %w", err)
+	}
+
+	_, tagPrefix, err := b.packageLocation(cwd)
+	if err != nil {
+		return err
+	}
+
+	latestTag, err := git.GetLatestTagFrom(b.conf.MainBranch, tagPrefix)
+	if err != nil {
+		return fmt.Errorf("error getting latest tag on %s: %w", b.conf.MainBranch, err)
+	}
+
+	startPoint := fromTag
+	baseTag := fromTag
+	if startPoint == "" {
+		startPoint = b.conf.MainBranch
+		baseTag = latestTag
+	} else if !strings.HasPrefix(fromTag, tagPrefix) {
+		return fmt.Errorf("tag %s isn't a tag of the %s package", fromTag, b.conf.PackagePath)
+	}
+
+	baseVersion, err := semver.NewVersion(strings.TrimPrefix(baseTag, tagPrefix))
+	if err != nil {
+		return fmt.Errorf("error parsing version from tag %s: %w", baseTag, err)
+	}
+
+	patchBumpVersion := baseVersion.IncPatch()
+	newVersion := fmt.Sprintf("v%s", patchBumpVersion.String())
+	hotfixBranch := b.conf.HotfixBranchPrefix + tagPrefix + newVersion
+
+	log.Debug().Msgf("Creating branch %s from %s", hotfixBranch, startPoint)
+	if err := git.CreateBranchFrom(hotfixBranch, startPoint); err != nil {
+		return fmt.Errorf("error creating hotfix branch: %w", err)
+	}
+
+	if baseTag != latestTag {
+		log.Info().Msgf(
+			"%s is older than the latest release %s, so the hotfix will be tagged on %s without merging it into %s or %s",
+			baseTag,
+			latestTag,
+			hotfixBranch,
+			b.conf.MainBranch,
+			b.conf.DevBranch,
+		)
+	}
+
+	log.Info().Msgf(
+		"Created hotfix branch %s from %s - commit your fix and add it to the changelog, then run `bumper hotfix finish`",
+		hotfixBranch,
+		startPoint,
+	)
+	return nil
+}
+
+// FinishHotfix bumps the version on the current hotfix branch, then merges it into main, tags it and merges it
+// back into dev. Maintenance hotfixes are tagged and pushed without being merged.
+func (b *Bumper) FinishHotfix() error {
+	if b.conf.BranchingModel != BranchingModelGitFlow {
+		return fmt.Errorf("hotfixes are only supported with the git-flow branching model, not %s", b.conf.BranchingModel)
+	}
+
+	git := GitWrapper{DryRun: b.conf.DryRun}
+
+	journalPath, err := checkNoIncompleteBump(&git)
+	if err != nil {
+		return err
+	}
+
+	currentBranch, err := git.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("error getting current branch: %w", err)
+	}
+
+	if !strings.HasPrefix(currentBranch, b.conf.HotfixBranchPrefix) {
+		return fmt.Errorf("expected to be on a %s* branch, got %s", b.conf.HotfixBranchPrefix, currentBranch)
+	}
+
+	bump, err := b.newBumpContext(&git)
+	if err != nil {
+		return err
+	}
+
+	newVersion := strings.TrimPrefix(currentBranch, b.conf.HotfixBranchPrefix+bump.TagPrefix)
+	if _, err := semver.NewVersion(newVersion); err != nil {
+		return fmt.Errorf("error parsing version from hotfix branch %s: %w", currentBranch, err)
+	}
+
+	// If main has been released since the hotfix branch was created from it, or the hotfix is for an older
+	// release, merging it would take main back to an older version.
+	latestMainTag, err := git.GetLatestTagFrom(b.conf.MainBranch, bump.TagPrefix)
+	if err != nil {
+		return fmt.Errorf("error getting latest tag on %s: %w", b.conf.MainBranch, err)
+	}
+
+	maintenance := latestMainTag != bump.PreviousTag()
+	if maintenance {
+		log.Info().Msgf(
+			"%s is already on %s, so the hotfix will be tagged on %s without merging it",
+			latestMainTag,
+			b.conf.MainBranch,
+			currentBranch,
+		)
+	}
+
+	log.Info().Msgf("Bumping hotfix version from %s to %s", bump.PreviousTag(), bump.TagPrefix+newVersion)
+
+	bump.model = newHotfixModel(b.conf, currentBranch, maintenance)
+	bump.Hotfix = true
+	bump.Maintenance = maintenance
+	bump.MergeRequest = b.conf.MergeRequest && !maintenance
+	bump.NewVersion = newVersion
+	bump.ReleaseBranch = currentBranch
+
+	return b.runBump(bump, journalPath)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// jsonString is the position of a string value in a JSON file, excluding the quotes, along with the path of keys
+// (or array indexes) to it from the root, e.g. ["packages", "", "version"].
+type jsonString struct {
+	path  []string
+	start int
+	end   int
+	value string
+}
+
+// jsonContainer keeps track of where we are in an object or array while walking through a JSON file.
+type jsonContainer struct {
+	isObject  bool
+	expectKey bool
+	key       string
+	index     int
+}
+
+func (c *jsonContainer) pathElement() string {
+	if c.isObject {
+		return c.key
+	}
+
+	return strconv.Itoa(c.index)
+}
+
+// valueDone moves on to the next key or array element once a value has been read.
+func (c *jsonContainer) valueDone() {
+	if c.isObject {
+		c.expectKey = true
+	} else {
+		c.index++
+	}
+}
+
+// findJSONStrings finds every string value in a JSON file, so that the values can be replaced without re-encoding
+// (and so reformatting and reordering) the whole file. Keys aren't included.
+func findJSONStrings(contents []byte) ([]jsonString, error) {
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+
+	var strs []jsonString
+	var stack []*jsonContainer
+
+	for {
+		tokenStart := int(decoder.InputOffset())
+
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("error parsing JSON: %w", err)
+		}
+
+		var top *jsonContainer
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		switch t := token.(type) {
+		case json.Delim:
+			switch t {
+			case '{':
+				stack = append(stack, &jsonContainer{isObject: true, expectKey: true})
+			case '[':
+				stack = append(stack, &jsonContainer{})
+			default:
+				stack = stack[:len(stack)-1]
+				if len(stack) > 0 {
+					stack[len(stack)-1].valueDone()
+				}
+			}
+		case string:
+			if top != nil && top.isObject && top.expectKey {
+				top.key = t
+				top.expectKey = false
+				continue
+			}
+
+			// Only whitespace, colons and commas can come between the previous token and the opening quote.
+			end := int(decoder.InputOffset()) - 1
+			start := tokenStart + bytes.IndexByte(contents[tokenStart:end], '"') + 1
+
+			path := make([]string, 0, len(stack))
+			for _, container := range stack {
+				path = append(path, container.pathElement())
+			}
+
+			strs = append(strs, jsonString{path: path, start: start, end: end, value: t})
+
+			if top != nil {
+				top.valueDone()
+			}
+		default:
+			if top != nil {
+				top.valueDone()
+			}
+		}
+	}
+
+	return strs, nil
+}
+
+// findJSONString finds the string value at the given path, returning nil if there isn't one.
+func findJSONString(strs []jsonString, path ...string) *jsonString {
+	for i := range strs {
+		if slices.Equal(strs[i].path, path) {
+			return &strs[i]
+		}
+	}
+
+	return nil
+}
+
+// replaceJSONStrings replaces each of the strings in the file with its value.
+func replaceJSONStrings(contents []byte, strs []jsonString) ([]byte, error) {
+	result := bytes.Clone(contents)
+
+	// Go backwards so that the earlier offsets are still valid.
+	sorted := slices.SortedFunc(slices.Values(strs), func(a jsonString, b jsonString) int { return b.start - a.start })
+
+	for _, str := range sorted {
+		var encoded bytes.Buffer
+		encoder := json.NewEncoder(&encoded)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(str.value); err != nil {
+			return nil, fmt.Errorf("error encoding JSON string: %w", err)
+		}
+
+		// Strip the quotes and trailing newline.
+		escapedValue := bytes.TrimSuffix(encoded.Bytes(), []byte("\n"))
+		escapedValue = escapedValue[1 : len(escapedValue)-1]
+
+		result = slices.Concat(result[:str.start], escapedValue, result[str.end:])
+	}
+
+	return result, nil
+}
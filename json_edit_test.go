@@ -0,0 +1,86 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindJSONStrings(t *testing.T) {
+	contents := "{\r\n" +
+		`  "name": "demo",` + "\r\n" +
+		`  "version": "1.2.3",` + "\r\n" +
+		`  "private": true,` + "\r\n" +
+		`  "files": ["dist", {"src": "lib"}, null, "\"quoted\" é"],` + "\r\n" +
+		`  "packages": {"": {"version": "1.2.3"}, "packages/a": {"count": 3, "version": "1.2.3"}},` + "\r\n" +
+		`  "version-key": {"version": 1}` + "\r\n" +
+		"}\r\n"
+
+	strs, err := findJSONStrings([]byte(contents))
+	if err != nil {
+		t.Fatalf("findJSONStrings() error = %v", err)
+	}
+
+	want := []struct {
+		path  []string
+		value string
+		raw   string
+	}{
+		{[]string{"name"}, "demo", "demo"},
+		{[]string{"version"}, "1.2.3", "1.2.3"},
+		{[]string{"files", "0"}, "dist", "dist"},
+		{[]string{"files", "1", "src"}, "lib", "lib"},
+		{[]string{"files", "3"}, `"quoted" é`, `\"quoted\" é`},
+		{[]string{"packages", "", "version"}, "1.2.3", "1.2.3"},
+		{[]string{"packages", "packages/a", "version"}, "1.2.3", "1.2.3"},
+	}
+
+	if len(strs) != len(want) {
+		t.Fatalf("got %d strings, want %d: %+v", len(strs), len(want), strs)
+	}
+
+	for i, str := range strs {
+		if !slices.Equal(str.path, want[i].path) || str.value != want[i].value {
+			t.Errorf("strs[%d] = %v %q, want %v %q", i, str.path, str.value, want[i].path, want[i].value)
+		}
+
+		if raw := contents[str.start:str.end]; raw != want[i].raw {
+			t.Errorf("strs[%d] text at span = %q, want %q", i, raw, want[i].raw)
+		}
+	}
+
+	if str := findJSONString(strs, "packages", "packages/a", "version"); str == nil || str.value != "1.2.3" {
+		t.Errorf("findJSONString() = %+v, want packages/a version", str)
+	}
+
+	if str := findJSONString(strs, "description"); str != nil {
+		t.Errorf("findJSONString() = %+v, want nil", str)
+	}
+
+	if _, err := findJSONStrings([]byte(`{"version": "1.2.3",}`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestReplaceJSONStrings(t *testing.T) {
+	contents := "{\r\n\t\"name\":\"demo\",\r\n\t\"version\" :  \"1.2.3\",\r\n\t\"deps\": { \"a\": \"^1.2.3\" }\r\n}"
+
+	strs, err := findJSONStrings([]byte(contents))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version := findJSONString(strs, "version")
+	version.value = `2.0.0-"<&>"`
+	dep := findJSONString(strs, "deps", "a")
+	dep.value = "^2.0.0"
+
+	got, err := replaceJSONStrings([]byte(contents), []jsonString{*version, *dep})
+	if err != nil {
+		t.Fatalf("replaceJSONStrings() error = %v", err)
+	}
+
+	want := "{\r\n\t\"name\":\"demo\",\r\n\t\"version\" :  \"2.0.0-\\\"<&>\\\"\",\r\n\t\"deps\": { \"a\": \"^2.0.0\" }\r\n}"
+	if string(got) != want {
+		t.Errorf("replaceJSONStrings() = %q, want %q", got, want)
+	}
+}
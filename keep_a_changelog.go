@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// inferBumpTypeFromChangelog works out the bump type from the Keep a Changelog subsections of the unreleased
+// changelog section which have content. Removals and breaking changes give a major bump, additions and other
+// changes give a minor bump, and only fixes and security fixes give a patch bump. The reasons are the subsections
+// which triggered the chosen bump.
+func inferBumpTypeFromChangelog(subsections []string) (BumpType, []string, error) {
+	reasonsByType := make(map[BumpType][]string)
+
+	for _, subsection := range subsections {
+		bumpType := changelogSubsectionBumpType(subsection)
+		reasonsByType[bumpType] = append(reasonsByType[bumpType], subsection)
+	}
+
+	for _, bumpType := range []BumpType{BumpTypeMajor, BumpTypeMinor, BumpTypePatch} {
+		if reasons, ok := reasonsByType[bumpType]; ok {
+			return bumpType, reasons, nil
+		}
+	}
+
+	return 0, nil, errors.New("no unreleased changes found in CHANGELOG.md")
+}
+
+func changelogSubsectionBumpType(subsection string) BumpType {
+	name := strings.ToLower(subsection)
+
+	switch {
+	case name == "removed" || strings.Contains(name, "breaking"):
+		return BumpTypeMajor
+	case name == "fixed" || name == "security":
+		return BumpTypePatch
+	default:
+		// Added, Changed, Deprecated and anything else we don't recognise.
+		return BumpTypeMinor
+	}
+}
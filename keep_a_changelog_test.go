@@ -0,0 +1,79 @@
+package main
+
+import (
+	"path"
+	"slices"
+	"testing"
+)
+
+func TestInferBumpTypeFromChangelog(t *testing.T) {
+	tests := []struct {
+		name        string
+		subsections []string
+		want        BumpType
+		wantReasons []string
+		wantErr     bool
+	}{
+		{"fixes", []string{"Fixed", "Security"}, BumpTypePatch, []string{"Fixed", "Security"}, false},
+		{"additions", []string{"Fixed", "Added", "Deprecated"}, BumpTypeMinor, []string{"Added", "Deprecated"}, false},
+		{"unknown subsection", []string{"Fixed", "Performance"}, BumpTypeMinor, []string{"Performance"}, false},
+		{"removals", []string{"Added", "removed"}, BumpTypeMajor, []string{"removed"}, false},
+		{"breaking changes", []string{"Fixed", "Breaking Changes"}, BumpTypeMajor, []string{"Breaking Changes"}, false},
+		{"nothing", nil, 0, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reasons, err := inferBumpTypeFromChangelog(tt.subsections)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("inferBumpTypeFromChangelog() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			if got != tt.want || !slices.Equal(reasons, tt.wantReasons) {
+				t.Errorf("inferBumpTypeFromChangelog() = %s, %v, want %s, %v", got, reasons, tt.want, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func TestGetUnreleasedSubsections(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "subsections with content",
+			contents: "## [Unreleased]\n\n### Added\n\n- Thing\n\n### Fixed\n\n–\n\n### Removed\n\n" +
+				"## [1.0.0] - 2024-01-01\n\n### Removed\n\n- Old thing\n",
+			want: []string{"Added"},
+		},
+		{
+			name:     "no subsections",
+			contents: "## Unreleased\n\n- Thing\n",
+			want:     nil,
+		},
+		{
+			name:     "no unreleased section",
+			contents: "## v1.0.0\n\n### Fixed\n\n- Bug\n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			writeTestFile(t, path.Join(projectPath, "CHANGELOG.md"), tt.contents)
+
+			got, err := NewChangelogUpdater(projectPath, &DiskFileStore{}).GetUnreleasedSubsections()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetUnreleasedSubsections() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("GetUnreleasedSubsections() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrBumpPaused is returned by a bump step when the bump can't continue until something happens outside of
+// bumper, e.g. a merge request being merged. The journal is kept so that the bump can be continued with
+// `bumper resume`.
+var ErrBumpPaused = errors.New("version bump paused")
+
+// MergeRequest is a merge request (or pull request, depending on the server) opened for a release branch.
+type MergeRequest struct {
+	ID  int
+	URL *url.URL
+}
+
+// MergeRequestCreator is implemented by release creators which can also open merge requests. This is used
+// instead of merging release branches locally when the target branch is protected.
+type MergeRequestCreator interface {
+	CreateMergeRequest(sourceBranch string, targetBranch string, title string, description string) (*MergeRequest, error)
+	EnableAutoMerge(id int) error
+
+	// GetMergeCommit returns the hash of the commit which the merge request was merged as (i.e. the merge or
+	// squash commit on the target branch), or an empty string if it hasn't been merged yet.
+	GetMergeCommit(id int) (string, error)
+}
+
+// mergeRequestSteps pushes the release branch and opens a merge request into the target branch instead of
+// merging it locally. The bump is paused until the merge request is merged, then the target branch is pulled
+// and the merge commit is tagged. If the dev branch is set, the target branch is also merged back into it.
+func mergeRequestSteps(c *bumpContext, targetBranch string, devBranch string) []BumpStep {
+	steps := []BumpStep{
+		{
+			Name:      "push release branch",
+			Run:       func() error { return c.git.PushBranch(c.ReleaseBranch) },
+			Published: true,
+		},
+		{
+			Name:      fmt.Sprintf("open merge request into %s", targetBranch),
+			Run:       func() error { return c.openMergeRequest(targetBranch) },
+			Published: true,
+		},
+		{
+			Name: "wait for merge request to be merged",
+			Run:  c.waitForMergeRequest,
+		},
+		{
+			Name: fmt.Sprintf("pull %s", targetBranch),
+			Run:  func() error { return c.pullBranch(targetBranch) },
+		},
+		{
+			Name: "tag release",
+			Run:  c.tagMergeCommit,
+		},
+		{
+			Name:      "push tags",
+			Run:       c.git.PushTags,
+			Published: true,
+		},
+	}
+
+	if devBranch != "" {
+		steps = append(steps, []BumpStep{
+			{
+				Name: fmt.Sprintf("merge %s into %s", targetBranch, devBranch),
+				Run:  func() error { return c.mergeBranch(targetBranch, devBranch) },
+			},
+			{
+				Name:      fmt.Sprintf("push %s", devBranch),
+				Run:       c.git.Push,
+				Published: true,
+			},
+		}...)
+	}
+
+	return append(steps, BumpStep{
+		Name: "delete local release branch",
+		// The release branch might have been squashed or rebased, so git won't necessarily see it as merged.
+		Run: func() error {
+			if !c.git.BranchExists(c.ReleaseBranch) {
+				return nil
+			}
+
+			return c.git.ForceDeleteBranch(c.ReleaseBranch)
+		},
+	})
+}
+
+func (c *bumpContext) mergeRequestCreator() (MergeRequestCreator, error) {
+	mergeRequestCreator, ok := c.releaseCreator.(MergeRequestCreator)
+	if !ok {
+		return nil, fmt.Errorf("%s doesn't support creating merge requests", c.releaseCreator.Name())
+	}
+
+	return mergeRequestCreator, nil
+}
+
+func (c *bumpContext) openMergeRequest(targetBranch string) error {
+	title := fmt.Sprintf("Release %s", c.NewTag())
+
+	if c.conf.DryRun {
+		log.Info().Msgf(
+			"Dry run: would open %s merge request '%s' from %s into %s",
+			c.releaseCreator.Name(),
+			title,
+			c.ReleaseBranch,
+			targetBranch,
+		)
+		return nil
+	}
+
+	mergeRequestCreator, err := c.mergeRequestCreator()
+	if err != nil {
+		return err
+	}
+
+	mergeRequest, err := mergeRequestCreator.CreateMergeRequest(c.ReleaseBranch, targetBranch, title, c.ReleaseNotes)
+	if err != nil {
+		return fmt.Errorf("error creating merge request: %w", err)
+	}
+
+	c.MergeRequestID = mergeRequest.ID
+	log.Info().Msgf("Opened %s merge request: %s", c.releaseCreator.Name(), mergeRequest.URL.String())
+
+	if c.conf.AutoMerge {
+		if err := mergeRequestCreator.EnableAutoMerge(mergeRequest.ID); err != nil {
+			// The merge request is still there, so it can just be merged by hand.
+			log.Warn().Err(err).Msg("Unable to enable auto-merge - merge request will need merging manually")
+		} else {
+			log.Info().Msg("Enabled auto-merge")
+		}
+	}
+
+	return nil
+}
+
+func (c *bumpContext) waitForMergeRequest() error {
+	if c.conf.DryRun {
+		log.Info().Msg("Dry run: would wait for the merge request to be merged")
+		return nil
+	}
+
+	mergeRequestCreator, err := c.mergeRequestCreator()
+	if err != nil {
+		return err
+	}
+
+	mergeCommit, err := mergeRequestCreator.GetMergeCommit(c.MergeRequestID)
+	if err != nil {
+		return fmt.Errorf("error checking merge request status: %w", err)
+	}
+
+	if mergeCommit == "" {
+		log.Info().Msg("Once the merge request has been merged, run `bumper resume` to tag and create the release")
+		return ErrBumpPaused
+	}
+
+	log.Debug().Msgf("Merge request was merged as %s", mergeCommit)
+	c.MergeCommit = mergeCommit
+	return nil
+}
+
+// tagMergeCommit tags the commit which the merge request was merged as. This isn't necessarily the head of the
+// target branch, as other merge requests could have been merged since.
+func (c *bumpContext) tagMergeCommit() error {
+	if c.conf.DryRun {
+		log.Info().Msgf("Dry run: would tag the merge request's merge commit as %s", c.NewTag())
+		return nil
+	}
+
+	if c.MergeCommit == "" {
+		return errors.New("merge commit of the merge request is unknown, so the release can't be tagged")
+	}
+
+	if !c.git.IsAncestor(c.MergeCommit, "HEAD") {
+		return fmt.Errorf("merge commit %s isn't on the pulled branch", c.MergeCommit)
+	}
+
+	log.Debug().Msgf("Creating tag %s at %s", c.NewTag(), c.MergeCommit)
+	if err := c.git.TagCommit(c.NewTag(), c.MergeCommit); err != nil {
+		return err
+	}
+
+	c.TagCreated = true
+	return nil
+}
+
+func (c *bumpContext) pullBranch(branchName string) error {
+	if err := c.git.CheckoutBranch(branchName); err != nil {
+		return fmt.Errorf("error switching to %s branch: %w", branchName, err)
+	}
+
+	return c.git.Pull(branchName)
+}
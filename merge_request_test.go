@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeMergeRequestCreator opens merge requests without talking to a server. The merge request counts as merged
+// once mergeCommit is set.
+type fakeMergeRequestCreator struct {
+	mergeCommit   string
+	mergeRequests []string
+}
+
+func (f *fakeMergeRequestCreator) IsCorrectServer() bool {
+	return true
+}
+
+func (f *fakeMergeRequestCreator) CreateRelease(_ string, _ string, _ string, _ bool) (*url.URL, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeMergeRequestCreator) Name() string {
+	return "Fake"
+}
+
+func (f *fakeMergeRequestCreator) CreateMergeRequest(
+	sourceBranch string,
+	targetBranch string,
+	_ string,
+	_ string,
+) (*MergeRequest, error) {
+	f.mergeRequests = append(f.mergeRequests, sourceBranch+" -> "+targetBranch)
+	return &MergeRequest{ID: 7, URL: &url.URL{Scheme: "https", Host: "example.com", Path: "/merge_requests/7"}}, nil
+}
+
+func (f *fakeMergeRequestCreator) EnableAutoMerge(_ int) error {
+	return nil
+}
+
+func (f *fakeMergeRequestCreator) GetMergeCommit(_ int) (string, error) {
+	return f.mergeCommit, nil
+}
+
+// newMergeRequestTestRepo creates a test repository with a local bare origin and a release branch with a version
+// bump commit on it.
+func newMergeRequestTestRepo(t *testing.T) string {
+	t.Helper()
+
+	newTestRepo(t)
+
+	originPath := path.Join(t.TempDir(), "origin.git")
+	runGit(t, "init", "-q", "--bare", "-b", "main", originPath)
+	runGit(t, "remote", "set-url", "origin", originPath)
+	runGit(t, "push", "-q", "origin", "main")
+
+	runGit(t, "checkout", "-q", "-b", "release/v1.1.0")
+	writeTestFile(t, "VERSION", "1.1.0\n")
+	runGit(t, "add", "VERSION")
+	runGit(t, "commit", "-q", "-m", "Bump version to v1.1.0")
+
+	return originPath
+}
+
+func newMergeRequestTestContext(creator *fakeMergeRequestCreator, state *BumpState) *bumpContext {
+	return &bumpContext{
+		conf:           &Config{},
+		git:            &GitWrapper{},
+		releaseCreator: creator,
+		BumpState:      state,
+	}
+}
+
+func TestMergeRequestStepsPauseAndResume(t *testing.T) {
+	originPath := newMergeRequestTestRepo(t)
+	captureLog(t)
+	journalPath := path.Join(t.TempDir(), "journal.json")
+	creator := &fakeMergeRequestCreator{}
+
+	state := &BumpState{NewVersion: "v1.1.0", ReleaseBranch: "release/v1.1.0", MergeRequest: true}
+	c := newMergeRequestTestContext(creator, state)
+
+	journal, err := NewBumpJournal(mergeRequestSteps(c, "main", ""), state, journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Run(); !errors.Is(err, ErrBumpPaused) {
+		t.Fatalf("Run() error = %v, want ErrBumpPaused", err)
+	}
+
+	state, err = LoadBumpState(journalPath)
+	if err != nil {
+		t.Fatalf("journal not kept when paused: %v", err)
+	}
+
+	wantCompleted := []string{"push release branch", "open merge request into main"}
+	if !slices.Equal(state.CompletedSteps, wantCompleted) {
+		t.Errorf("completed steps = %v, want %v", state.CompletedSteps, wantCompleted)
+	}
+
+	if state.MergeRequestID != 7 {
+		t.Errorf("merge request ID = %d, want 7", state.MergeRequestID)
+	}
+
+	if want := []string{"release/v1.1.0 -> main"}; !slices.Equal(creator.mergeRequests, want) {
+		t.Errorf("merge requests = %v, want %v", creator.mergeRequests, want)
+	}
+
+	if tags := runGit(t, "tag", "-l", "v1.1.0"); tags != "" {
+		t.Error("release tagged before the merge request was merged")
+	}
+
+	// Squash merge the merge request on the server, then merge something else on top of it.
+	forgePath := path.Join(t.TempDir(), "forge")
+	runGit(t, "clone", "-q", originPath, forgePath)
+	runGit(t, "-C", forgePath, "merge", "-q", "--squash", "origin/release/v1.1.0")
+	runGit(t, "-C", forgePath, "commit", "-q", "-m", "Release v1.1.0 (!7)")
+	squashCommit := runGit(t, "-C", forgePath, "rev-parse", "HEAD")
+	writeTestFile(t, path.Join(forgePath, "OTHER"), "other\n")
+	runGit(t, "-C", forgePath, "add", "OTHER")
+	runGit(t, "-C", forgePath, "commit", "-q", "-m", "Another change")
+	otherCommit := runGit(t, "-C", forgePath, "rev-parse", "HEAD")
+	runGit(t, "-C", forgePath, "push", "-q", "origin", "main")
+
+	creator.mergeCommit = squashCommit
+
+	c = newMergeRequestTestContext(creator, state)
+	journal, err = NewBumpJournal(mergeRequestSteps(c, "main", ""), state, journalPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := journal.Run(); err != nil {
+		t.Fatalf("resumed Run() error = %v", err)
+	}
+
+	if head := runGit(t, "rev-parse", "HEAD"); head != otherCommit {
+		t.Errorf("HEAD = %s, want the pulled main branch %s", head, otherCommit)
+	}
+
+	if tagged := runGit(t, "rev-parse", "v1.1.0^{commit}"); tagged != squashCommit {
+		t.Errorf("v1.1.0 tags %s, want the squash commit %s", tagged, squashCommit)
+	}
+
+	if remoteTags := runGit(t, "ls-remote", "--tags", "origin", "v1.1.0"); !strings.Contains(remoteTags, "v1.1.0") {
+		t.Error("tag v1.1.0 not pushed")
+	}
+
+	if c.git.BranchExists("release/v1.1.0") {
+		t.Error("local release branch not deleted")
+	}
+
+	if fileExists(journalPath) {
+		t.Error("journal not removed after the bump completed")
+	}
+}
+
+func TestTagMergeCommitErrors(t *testing.T) {
+	newMergeRequestTestRepo(t)
+	unmergedCommit := runGit(t, "rev-parse", "HEAD")
+	runGit(t, "checkout", "-q", "main")
+
+	tests := []struct {
+		name        string
+		mergeCommit string
+		wantErr     string
+	}{
+		{"unknown merge commit", "", "merge commit of the merge request is unknown"},
+		{"merge commit not on branch", unmergedCommit, "isn't on the pulled branch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &BumpState{NewVersion: "v1.1.0", MergeCommit: tt.mergeCommit}
+			c := newMergeRequestTestContext(&fakeMergeRequestCreator{}, state)
+
+			if err := c.tagMergeCommit(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("tagMergeCommit() error = %v, want error containing %q", err, tt.wantErr)
+			}
+
+			if tags := runGit(t, "tag", "-l", "v1.1.0"); tags != "" {
+				t.Error("tag created despite the error")
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"errors"
 	"os"
+
+	"github.com/rs/zerolog/log"
 )
 
 var ErrPackageNotFound = errors.New("package file(s) not found")
@@ -11,24 +13,79 @@ type Packager interface {
 	Parse(projectPath string) error
 	Name() string
 	Version() string
-	PackageFilePath() string
+
+	// PackageFilePaths returns the files which are changed when bumping the version.
+	PackageFilePaths() []string
+
 	BumpVersion(newVersion string) error
 }
 
-func packagerForProject(projectPath string) Packager {
+// DevelopmentVersioner is implemented by packagers which use a different version on the development branch
+// between releases, e.g. Maven's -SNAPSHOT versions.
+type DevelopmentVersioner interface {
+	// DevelopmentVersion returns the version to set on the development branch after releasing the given version.
+	DevelopmentVersion(releasedVersion string) (string, error)
+}
+
+// TagVersioner is implemented by packagers which are versioned by the tags rather than a version in the package
+// files, and so need to be told the version being bumped from, e.g. go mod.
+type TagVersioner interface {
+	SetTagVersion(version string)
+}
+
+// AppVersioner is implemented by packagers which have their own version as well as the version of the application
+// they package, e.g. a Helm chart's version and appVersion.
+type AppVersioner interface {
+	// AppVersion returns the version of the packaged application, or an empty string if it isn't being bumped.
+	AppVersion() string
+
+	// BumpAppVersion bumps the application from the old to the new version, bumping the packager's own version by
+	// the same kind of change.
+	BumpAppVersion(oldVersion string, newVersion string) error
+}
+
+// packagerForProject returns the packager for the project, or nil if none apply. If more than one applies, e.g. a
+// Python project with an npm frontend, they're combined into a MultiPackager which bumps them all, as long as they
+// agree on the current version.
+func packagerForProject(projectPath string, store FileStore, conf *Config) (Packager, error) {
 	packagers := []Packager{
-		&GoModPackager{},
-		&PyprojectPackager{},
-		&NPMPackager{},
+		&VersionFilesPackager{store: store, versionFiles: conf.VersionFiles},
+		&GoModPackager{store: store},
+		&PyprojectPackager{store: store},
+		&NPMPackager{store: store},
+		&CargoPackager{store: store},
+		&MavenPackager{store: store},
+		&GradlePackager{store: store, incrementVersionCode: conf.AndroidVersionCode},
+		&DotNetPackager{store: store},
+		&HelmPackager{store: store, syncAppVersion: conf.HelmAppVersion},
 	}
 
+	var applicablePackagers []Packager
 	for _, packager := range packagers {
 		if err := packager.Parse(projectPath); err == nil {
-			return packager
+			applicablePackagers = append(applicablePackagers, packager)
+		} else if !errors.Is(err, ErrPackageNotFound) {
+			log.Warn().Err(err).Msgf("Unable to use %s package - its version will not be bumped", packager.Name())
 		}
 	}
 
-	return nil
+	switch len(applicablePackagers) {
+	case 0:
+		return nil, nil
+	case 1:
+		return applicablePackagers[0], nil
+	default:
+		return NewMultiPackager(applicablePackagers)
+	}
+}
+
+// splitPackagers returns the individual packagers making up the packager.
+func splitPackagers(packager Packager) []Packager {
+	if multiPackager, ok := packager.(*MultiPackager); ok {
+		return multiPackager.packagers
+	}
+
+	return []Packager{packager}
 }
 
 func fileExists(filePath string) bool {
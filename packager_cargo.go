@@ -0,0 +1,474 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Masterminds/semver"
+)
+
+var cargoDependencyTables = []string{"dependencies", "dev-dependencies", "build-dependencies"}
+
+// Matches `name = "1.2.3"` and `name = { path = "../name", version = "1.2.3" }` style dependencies.
+var cargoDependencyRe = regexp.MustCompile(`^\s*([A-Za-z0-9_-]+)\s*=`)
+var cargoInlineVersionRe = regexp.MustCompile(`(\bversion\s*=\s*)(["'])([^"']*)(["'])`)
+var cargoInlinePackageRe = regexp.MustCompile(`\bpackage\s*=\s*["']([^"']*)["']`)
+var cargoRequirementRe = regexp.MustCompile(`^(\s*(?:\^|=|~|>=)?\s*)(.*)$`)
+
+type cargoManifest struct {
+	Package *struct {
+		Name    string `toml:"name"`
+		Version any    `toml:"version"`
+	} `toml:"package"`
+
+	Workspace *struct {
+		Members []string `toml:"members"`
+		Exclude []string `toml:"exclude"`
+		Package *struct {
+			Version string `toml:"version"`
+		} `toml:"package"`
+	} `toml:"workspace"`
+}
+
+// inheritsVersion returns true if the package takes its version from the workspace with `version.workspace = true`.
+func (m *cargoManifest) inheritsVersion() bool {
+	if m.Package == nil {
+		return false
+	}
+
+	version, ok := m.Package.Version.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	inherit, _ := version["workspace"].(bool)
+	return inherit
+}
+
+// CargoPackager bumps the version of a Rust crate, or of all the crates in a workspace which inherit the version
+// from `[workspace.package]`. Requirements on the bumped crates from other crates in the workspace and the
+// entries in Cargo.lock are updated to match.
+type CargoPackager struct {
+	store FileStore
+
+	// The manifest which the version is defined in, and the table it's in.
+	packageFilePath string
+	versionTable    string
+
+	// The crates which get the new version, and all the manifests in the workspace which might depend on them.
+	crateNames    []string
+	manifestPaths []string
+	lockFilePath  string
+
+	version semver.Version
+
+	// Other files which were changed when bumping the version, e.g. manifests with updated dependencies.
+	changedFilePaths []string
+}
+
+func (p *CargoPackager) Parse(projectPath string) error {
+	manifestPath := path.Join(projectPath, "Cargo.toml")
+
+	if !fileExists(manifestPath) {
+		return ErrPackageNotFound
+	}
+
+	manifest, err := p.readManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	workspaceRoot := projectPath
+	workspaceManifest := manifest
+	if manifest.Workspace == nil {
+		workspaceRoot, workspaceManifest = p.findWorkspaceRoot(projectPath)
+	}
+
+	var versionRaw string
+	switch {
+	case manifest.inheritsVersion() || (manifest.Package == nil && manifest.Workspace != nil):
+		if workspaceManifest == nil || workspaceManifest.Workspace.Package == nil ||
+			workspaceManifest.Workspace.Package.Version == "" {
+			return errors.New("version not found in [workspace.package] of workspace Cargo.toml")
+		}
+
+		versionRaw = workspaceManifest.Workspace.Package.Version
+		p.packageFilePath = path.Join(workspaceRoot, "Cargo.toml")
+		p.versionTable = "workspace.package"
+	case manifest.Package != nil:
+		var ok bool
+		versionRaw, ok = manifest.Package.Version.(string)
+		if !ok {
+			return errors.New("version not found in [package] of Cargo.toml")
+		}
+
+		p.packageFilePath = manifestPath
+		p.versionTable = "package"
+	default:
+		return errors.New("no [package] or [workspace] found in Cargo.toml")
+	}
+
+	version, err := semver.NewVersion(versionRaw)
+	if err != nil {
+		return errors.New("invalid semver version")
+	}
+
+	p.version = *version
+	p.lockFilePath = path.Join(projectPath, "Cargo.lock")
+	p.manifestPaths = nil
+	p.crateNames = nil
+
+	if workspaceManifest != nil {
+		if err := p.parseWorkspace(workspaceRoot, workspaceManifest); err != nil {
+			return err
+		}
+	}
+
+	// Make sure the crate itself is included, even if it's not listed in the workspace members.
+	if !slices.Contains(p.manifestPaths, manifestPath) {
+		p.manifestPaths = append(p.manifestPaths, manifestPath)
+	}
+
+	if manifest.Package != nil && !slices.Contains(p.crateNames, manifest.Package.Name) {
+		p.crateNames = append(p.crateNames, manifest.Package.Name)
+	}
+
+	return nil
+}
+
+// parseWorkspace finds all the manifests in the workspace, and which crates share the version being bumped.
+func (p *CargoPackager) parseWorkspace(workspaceRoot string, workspaceManifest *cargoManifest) error {
+	p.lockFilePath = path.Join(workspaceRoot, "Cargo.lock")
+
+	rootManifestPath := path.Join(workspaceRoot, "Cargo.toml")
+	memberPaths := []string{rootManifestPath}
+
+	for _, memberGlob := range workspaceManifest.Workspace.Members {
+		memberDirs, err := filepath.Glob(path.Join(workspaceRoot, memberGlob))
+		if err != nil {
+			return fmt.Errorf("error finding workspace members matching %s: %w", memberGlob, err)
+		}
+
+		for _, memberDir := range memberDirs {
+			memberPath := path.Join(memberDir, "Cargo.toml")
+			if fileExists(memberPath) && !p.isExcluded(workspaceRoot, workspaceManifest, memberDir) {
+				memberPaths = append(memberPaths, memberPath)
+			}
+		}
+	}
+
+	p.manifestPaths = nil
+	p.crateNames = nil
+	for _, memberPath := range memberPaths {
+		if slices.Contains(p.manifestPaths, memberPath) {
+			continue
+		}
+
+		member, err := p.readManifest(memberPath)
+		if err != nil {
+			return err
+		}
+
+		p.manifestPaths = append(p.manifestPaths, memberPath)
+
+		if member.Package == nil {
+			continue
+		}
+
+		// Crates with their own version are only bumped if it's the crate we're bumping.
+		sharesVersion := member.inheritsVersion()
+		if p.versionTable == "package" {
+			sharesVersion = memberPath == p.packageFilePath
+		}
+
+		if sharesVersion {
+			p.crateNames = append(p.crateNames, member.Package.Name)
+		}
+	}
+
+	return nil
+}
+
+func (p *CargoPackager) isExcluded(workspaceRoot string, workspaceManifest *cargoManifest, memberDir string) bool {
+	for _, exclude := range workspaceManifest.Workspace.Exclude {
+		if path.Clean(path.Join(workspaceRoot, exclude)) == path.Clean(memberDir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findWorkspaceRoot looks for a Cargo.toml with a [workspace] table in the parent directories, returning nil if
+// the crate isn't part of a workspace.
+func (p *CargoPackager) findWorkspaceRoot(projectPath string) (string, *cargoManifest) {
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return "", nil
+	}
+
+	for dir := filepath.Dir(absPath); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		manifestPath := path.Join(dir, "Cargo.toml")
+		if !fileExists(manifestPath) {
+			continue
+		}
+
+		manifest, err := p.readManifest(manifestPath)
+		if err == nil && manifest.Workspace != nil {
+			return dir, manifest
+		}
+	}
+
+	return "", nil
+}
+
+func (p *CargoPackager) readManifest(manifestPath string) (*cargoManifest, error) {
+	manifestBytes, err := p.store.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", manifestPath, err)
+	}
+
+	var manifest cargoManifest
+	if err := toml.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", manifestPath, err)
+	}
+
+	return &manifest, nil
+}
+
+func (p *CargoPackager) Name() string {
+	return "cargo"
+}
+
+func (p *CargoPackager) Version() string {
+	return fmt.Sprintf("v%s", p.version.String())
+}
+
+func (p *CargoPackager) PackageFilePaths() []string {
+	return append([]string{p.packageFilePath}, p.changedFilePaths...)
+}
+
+func (p *CargoPackager) BumpVersion(newVersion string) error {
+	newVersion = strings.TrimPrefix(newVersion, "v")
+	oldVersion := p.version.String()
+
+	manifestBytes, err := p.store.ReadFile(p.packageFilePath)
+	if err != nil {
+		return fmt.Errorf("error reading Cargo.toml: %w", err)
+	}
+
+	editor := newTOMLEditor(manifestBytes)
+	if !editor.setString(p.versionTable, "version", newVersion) {
+		return fmt.Errorf("version not found in [%s] of %s", p.versionTable, p.packageFilePath)
+	}
+
+	if err := p.store.WriteFile(p.packageFilePath, editor.Bytes()); err != nil {
+		return fmt.Errorf("error writing Cargo.toml: %w", err)
+	}
+
+	for _, manifestPath := range p.manifestPaths {
+		if err := p.updateDependencies(manifestPath, oldVersion, newVersion); err != nil {
+			return err
+		}
+	}
+
+	if fileExists(p.lockFilePath) {
+		if err := p.updateLockFile(oldVersion, newVersion); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateDependencies updates the version requirements on the bumped crates in the given manifest.
+func (p *CargoPackager) updateDependencies(manifestPath string, oldVersion string, newVersion string) error {
+	manifestBytes, err := p.store.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", manifestPath, err)
+	}
+
+	editor := newTOMLEditor(manifestBytes)
+	versionKeyRe := tomlStringKeyRe("version")
+
+	changed := false
+	editor.forEachLine(func(table string, i int) {
+		line := editor.lines[i]
+		tableParts := strings.Split(table, ".")
+
+		// [dependencies.name] style tables, which have the version on its own line.
+		if len(tableParts) >= 2 && slices.Contains(cargoDependencyTables, tableParts[len(tableParts)-2]) {
+			crateName := tableParts[len(tableParts)-1]
+			if !slices.Contains(p.crateNames, crateName) {
+				return
+			}
+
+			if matches := versionKeyRe.FindStringSubmatch(line); matches != nil {
+				if requirement, ok := updateCargoRequirement(matches[3], oldVersion, newVersion); ok {
+					editor.lines[i] = versionKeyRe.ReplaceAllString(line, "${1}${2}"+requirement+"${4}")
+					changed = true
+				}
+			}
+
+			return
+		}
+
+		if !slices.Contains(cargoDependencyTables, tableParts[len(tableParts)-1]) {
+			return
+		}
+
+		matches := cargoDependencyRe.FindStringSubmatch(line)
+		if matches == nil {
+			return
+		}
+
+		crateName := matches[1]
+		if packageMatches := cargoInlinePackageRe.FindStringSubmatch(line); packageMatches != nil {
+			crateName = packageMatches[1]
+		}
+
+		if !slices.Contains(p.crateNames, crateName) {
+			return
+		}
+
+		// Either `name = "1.2.3"` or an inline table with a version key.
+		if simpleMatches := tomlStringKeyRe(matches[1]).FindStringSubmatch(line); simpleMatches != nil {
+			if requirement, ok := updateCargoRequirement(simpleMatches[3], oldVersion, newVersion); ok {
+				editor.lines[i] = simpleMatches[1] + simpleMatches[2] + requirement + simpleMatches[4]
+				changed = true
+			}
+			return
+		}
+
+		if versionMatches := cargoInlineVersionRe.FindStringSubmatchIndex(line); versionMatches != nil {
+			if requirement, ok := updateCargoRequirement(
+				line[versionMatches[6]:versionMatches[7]],
+				oldVersion,
+				newVersion,
+			); ok {
+				editor.lines[i] = line[:versionMatches[6]] + requirement + line[versionMatches[7]:]
+				changed = true
+			}
+		}
+	})
+
+	if !changed {
+		return nil
+	}
+
+	if err := p.store.WriteFile(manifestPath, editor.Bytes()); err != nil {
+		return fmt.Errorf("error writing %s: %w", manifestPath, err)
+	}
+
+	p.addChangedFile(manifestPath)
+	return nil
+}
+
+// updateCargoRequirement returns the updated version requirement if it pins the old version or doesn't allow the
+// new version, keeping any operator on the front, e.g. "=1.2.3" becomes "=1.3.0". Requirements like "1.2" which
+// still allow the new version are left alone.
+func updateCargoRequirement(requirement string, oldVersion string, newVersion string) (string, bool) {
+	matches := cargoRequirementRe.FindStringSubmatch(requirement)
+	operator, requiredVersion := matches[1], matches[2]
+
+	if requiredVersion == oldVersion {
+		return operator + newVersion, true
+	}
+
+	// Cargo treats requirements without an operator as caret requirements.
+	constraintStr := requirement
+	if trimmedOperator := strings.TrimSpace(operator); trimmedOperator == "" || trimmedOperator == "^" {
+		if caretConstraint, ok := caretConstraint(requiredVersion); ok {
+			constraintStr = caretConstraint
+		}
+	}
+
+	constraint, err := semver.NewConstraint(constraintStr)
+	if err != nil {
+		// Anything more complicated (e.g. multiple comparators) is left for the user to sort out.
+		return "", false
+	}
+
+	version, err := semver.NewVersion(newVersion)
+	if err != nil || constraint.Check(version) {
+		return "", false
+	}
+
+	return operator + newVersion, true
+}
+
+// updateLockFile updates the version of the bumped crates in Cargo.lock. Workspace crates don't have a source, which
+// distinguishes them from any crates.io packages with the same name.
+func (p *CargoPackager) updateLockFile(oldVersion string, newVersion string) error {
+	lockBytes, err := p.store.ReadFile(p.lockFilePath)
+	if err != nil {
+		return fmt.Errorf("error reading Cargo.lock: %w", err)
+	}
+
+	editor := newTOMLEditor(lockBytes)
+	nameKeyRe := tomlStringKeyRe("name")
+	versionKeyRe := tomlStringKeyRe("version")
+	sourceKeyRe := tomlStringKeyRe("source")
+
+	changed := false
+	name, versionLine, hasSource := "", -1, false
+
+	updatePackage := func() {
+		if versionLine >= 0 && !hasSource && slices.Contains(p.crateNames, name) {
+			line := editor.lines[versionLine]
+			if matches := versionKeyRe.FindStringSubmatch(line); matches != nil && matches[3] == oldVersion {
+				editor.lines[versionLine] = versionKeyRe.ReplaceAllString(line, "${1}${2}"+newVersion+"${4}")
+				changed = true
+			}
+		}
+
+		name, versionLine, hasSource = "", -1, false
+	}
+
+	lastTable := ""
+	editor.forEachLine(func(table string, i int) {
+		// Every [[package]] has the same table name, so look for the header to tell where each one starts.
+		if table != lastTable || (i > 0 && tomlTableHeaderRe.MatchString(editor.lines[i-1])) {
+			updatePackage()
+			lastTable = table
+		}
+
+		if table != "package" {
+			return
+		}
+
+		line := editor.lines[i]
+		switch {
+		case nameKeyRe.MatchString(line):
+			name = nameKeyRe.FindStringSubmatch(line)[3]
+		case versionKeyRe.MatchString(line):
+			versionLine = i
+		case sourceKeyRe.MatchString(line):
+			hasSource = true
+		}
+	})
+	updatePackage()
+
+	if !changed {
+		return nil
+	}
+
+	if err := p.store.WriteFile(p.lockFilePath, editor.Bytes()); err != nil {
+		return fmt.Errorf("error writing Cargo.lock: %w", err)
+	}
+
+	p.addChangedFile(p.lockFilePath)
+	return nil
+}
+
+func (p *CargoPackager) addChangedFile(filePath string) {
+	if filePath != p.packageFilePath && !slices.Contains(p.changedFilePaths, filePath) {
+		p.changedFilePaths = append(p.changedFilePaths, filePath)
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"path"
+	"slices"
+	"testing"
+)
+
+func TestUpdateCargoRequirement(t *testing.T) {
+	tests := []struct {
+		requirement string
+		newVersion  string
+		want        string
+		wantOK      bool
+	}{
+		{"1.2.3", "1.3.0", "1.3.0", true},
+		{"=1.2.3", "1.3.0", "=1.3.0", true},
+		{"~1.2.3", "1.2.4", "~1.2.4", true},
+		{"^ 1.2.3", "2.0.0", "^ 2.0.0", true},
+		{"1.2", "1.3.0", "", false},
+		{"1", "1.3.0", "", false},
+		{"1.2", "2.0.0", "2.0.0", true},
+		{"0.1", "0.1.9", "", false},
+		{"0.1", "0.2.0", "0.2.0", true},
+		{"^0.0.3", "0.0.4", "^0.0.4", true},
+		{"1.2.0-rc.1", "1.3.0", "", false},
+		{"~1.2", "1.3.0", "~1.3.0", true},
+		{"1.*", "1.3.0", "", false},
+		{"*", "2.0.0", "", false},
+		{"not a requirement", "2.0.0", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.requirement+" to "+tt.newVersion, func(t *testing.T) {
+			got, ok := updateCargoRequirement(tt.requirement, "1.2.3", tt.newVersion)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("updateCargoRequirement() = %q, %t, want %q, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCargoBumpCrate(t *testing.T) {
+	projectPath := t.TempDir()
+
+	manifest := `[package]
+name = "demo"
+version = "1.2.3" # the crate version
+description = """
+version = "1.2.3"
+"""
+
+[dependencies]
+serde = { version = "1.2.3", features = ["derive"] }
+
+[dependencies.log]
+version = "1.2.3"
+`
+	writeTestFile(t, path.Join(projectPath, "Cargo.toml"), manifest)
+
+	packager := &CargoPackager{store: &DiskFileStore{}}
+	if err := packager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := packager.Version(); got != "v1.2.3" {
+		t.Errorf("Version() = %s, want v1.2.3", got)
+	}
+
+	if err := packager.BumpVersion("v1.3.0"); err != nil {
+		t.Fatalf("BumpVersion() error = %v", err)
+	}
+
+	want := `[package]
+name = "demo"
+version = "1.3.0" # the crate version
+description = """
+version = "1.2.3"
+"""
+
+[dependencies]
+serde = { version = "1.2.3", features = ["derive"] }
+
+[dependencies.log]
+version = "1.2.3"
+`
+	assertFileContents(t, path.Join(projectPath, "Cargo.toml"), want)
+}
+
+func TestCargoBumpWorkspace(t *testing.T) {
+	projectPath := t.TempDir()
+
+	files := map[string]string{
+		"Cargo.toml": `[workspace]
+members = ["crates/*"]
+exclude = ["crates/excluded"]
+
+[workspace.package]
+version = "1.2.3"
+edition = "2021"
+
+[workspace.dependencies]
+core = { path = "crates/core", version = "=1.2.3" }
+`,
+		"crates/core/Cargo.toml": `[package]
+name = "core"
+version.workspace = true
+`,
+		"crates/cli/Cargo.toml": `[package]
+name = "cli"
+version.workspace = true
+
+[dependencies]
+core = { path = "../core", version = "1.2.3" }
+`,
+		"crates/tool/Cargo.toml": `[package]
+name = "tool"
+version = "0.1.0"
+
+[dependencies]
+core = "=1.2.3"
+renamed = { package = "core", version = "1.2" }
+
+[target.'cfg(unix)'.dev-dependencies.cli]
+path = "../cli"
+version = "~1.2.3"
+`,
+		"crates/excluded/Cargo.toml": `[package]
+name = "excluded"
+version = "0.1.0"
+
+[dependencies]
+core = "=1.2.3"
+`,
+		"Cargo.lock": `version = 3
+
+[[package]]
+name = "cli"
+version = "1.2.3"
+dependencies = [
+ "core",
+]
+
+[[package]]
+name = "core"
+version = "1.2.3"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "core"
+version = "1.2.3"
+
+[[package]]
+name = "tool"
+version = "0.1.0"
+`,
+	}
+	for filePath, contents := range files {
+		writeTestFile(t, path.Join(projectPath, filePath), contents)
+	}
+
+	packager := &CargoPackager{store: &DiskFileStore{}}
+	if err := packager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := packager.Version(); got != "v1.2.3" {
+		t.Errorf("Version() = %s, want v1.2.3", got)
+	}
+
+	if err := packager.BumpVersion("v1.3.0"); err != nil {
+		t.Fatalf("BumpVersion() error = %v", err)
+	}
+
+	want := map[string]string{
+		"Cargo.toml": `[workspace]
+members = ["crates/*"]
+exclude = ["crates/excluded"]
+
+[workspace.package]
+version = "1.3.0"
+edition = "2021"
+
+[workspace.dependencies]
+core = { path = "crates/core", version = "=1.3.0" }
+`,
+		"crates/core/Cargo.toml": files["crates/core/Cargo.toml"],
+		"crates/cli/Cargo.toml": `[package]
+name = "cli"
+version.workspace = true
+
+[dependencies]
+core = { path = "../core", version = "1.3.0" }
+`,
+		"crates/tool/Cargo.toml": `[package]
+name = "tool"
+version = "0.1.0"
+
+[dependencies]
+core = "=1.3.0"
+renamed = { package = "core", version = "1.2" }
+
+[target.'cfg(unix)'.dev-dependencies.cli]
+path = "../cli"
+version = "~1.3.0"
+`,
+		"crates/excluded/Cargo.toml": files["crates/excluded/Cargo.toml"],
+		"Cargo.lock": `version = 3
+
+[[package]]
+name = "cli"
+version = "1.3.0"
+dependencies = [
+ "core",
+]
+
+[[package]]
+name = "core"
+version = "1.2.3"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "core"
+version = "1.3.0"
+
+[[package]]
+name = "tool"
+version = "0.1.0"
+`,
+	}
+	for filePath, contents := range want {
+		assertFileContents(t, path.Join(projectPath, filePath), contents)
+	}
+
+	wantPaths := []string{
+		path.Join(projectPath, "Cargo.toml"),
+		path.Join(projectPath, "crates/cli/Cargo.toml"),
+		path.Join(projectPath, "crates/tool/Cargo.toml"),
+		path.Join(projectPath, "Cargo.lock"),
+	}
+	gotPaths := packager.PackageFilePaths()
+	if len(gotPaths) != len(wantPaths) {
+		t.Fatalf("PackageFilePaths() = %v, want %v", gotPaths, wantPaths)
+	}
+
+	for _, wantPath := range wantPaths {
+		if !slices.Contains(gotPaths, wantPath) {
+			t.Errorf("PackageFilePaths() = %v, missing %s", gotPaths, wantPath)
+		}
+	}
+}
+
+func TestCargoParseMemberOfWorkspace(t *testing.T) {
+	projectPath := t.TempDir()
+
+	writeTestFile(
+		t,
+		path.Join(projectPath, "Cargo.toml"),
+		"[workspace]\nmembers = [\"api\"]\n\n[workspace.package]\nversion = \"2.0.0\"\n",
+	)
+	writeTestFile(
+		t,
+		path.Join(projectPath, "api/Cargo.toml"),
+		"[package]\nname = \"api\"\nversion = { workspace = true }\n",
+	)
+
+	packager := &CargoPackager{store: &DiskFileStore{}}
+	if err := packager.Parse(path.Join(projectPath, "api")); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := packager.Version(); got != "v2.0.0" {
+		t.Errorf("Version() = %s, want v2.0.0", got)
+	}
+
+	if got := packager.PackageFilePaths()[0]; got != path.Join(projectPath, "Cargo.toml") {
+		t.Errorf("PackageFilePaths()[0] = %s, want the workspace Cargo.toml", got)
+	}
+}
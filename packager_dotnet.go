@@ -0,0 +1,267 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+const dotnetPropsFileName = "Directory.Build.props"
+
+// How many directories deep to look for project files, e.g. src/MyLibrary/MyLibrary.csproj.
+const dotnetMaxProjectDepth = 3
+
+var dotnetSkipDirs = []string{".git", "bin", "obj", "node_modules"}
+
+// dotnetVersionFile is a project or props file which sets the version.
+type dotnetVersionFile struct {
+	filePath string
+
+	// Either the full version is in <Version>, or the release part is in <VersionPrefix> and the pre-release part
+	// is in <VersionSuffix>.
+	usesPrefix bool
+	hasSuffix  bool
+}
+
+// DotNetPackager bumps the version in a shared Directory.Build.props or in the .csproj files, along with
+// <AssemblyVersion> and <FileVersion> if they're set. The XML is edited in place so that the formatting is preserved.
+type DotNetPackager struct {
+	store        FileStore
+	versionFiles []dotnetVersionFile
+	version      semver.Version
+}
+
+func (p *DotNetPackager) Parse(projectPath string) error {
+	propsFilePath := path.Join(projectPath, dotnetPropsFileName)
+
+	var candidateFilePaths []string
+	if fileExists(propsFilePath) {
+		candidateFilePaths = append(candidateFilePaths, propsFilePath)
+	}
+
+	projectFilePaths, err := p.findProjectFiles(projectPath)
+	if err != nil {
+		return err
+	}
+	candidateFilePaths = append(candidateFilePaths, projectFilePaths...)
+
+	if len(candidateFilePaths) == 0 {
+		return ErrPackageNotFound
+	}
+
+	p.versionFiles = nil
+	for _, filePath := range candidateFilePaths {
+		if err := p.parseVersionFile(filePath); err != nil {
+			return err
+		}
+	}
+
+	if len(p.versionFiles) == 0 {
+		return errors.New("no <Version> or <VersionPrefix> found in Directory.Build.props or project files")
+	}
+
+	return nil
+}
+
+// findProjectFiles finds the .csproj files in the project, skipping build output directories.
+func (p *DotNetPackager) findProjectFiles(projectPath string) ([]string, error) {
+	var projectFilePaths []string
+
+	err := filepath.WalkDir(projectPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() && filePath != projectPath {
+			relPath, err := filepath.Rel(projectPath, filePath)
+			if err != nil {
+				return err
+			}
+
+			depth := len(strings.Split(relPath, string(filepath.Separator)))
+			if slices.Contains(dotnetSkipDirs, entry.Name()) || depth > dotnetMaxProjectDepth {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if strings.HasSuffix(entry.Name(), ".csproj") {
+			projectFilePaths = append(projectFilePaths, filePath)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error finding project files: %w", err)
+	}
+
+	return projectFilePaths, nil
+}
+
+// parseVersionFile adds the file if it sets the version, making sure it agrees with any versions already found.
+func (p *DotNetPackager) parseVersionFile(filePath string) error {
+	fileBytes, err := p.store.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filePath, err)
+	}
+
+	versionSpans, err := findDotNetProperty(fileBytes, "Version")
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", filePath, err)
+	}
+
+	prefixSpans, err := findDotNetProperty(fileBytes, "VersionPrefix")
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", filePath, err)
+	}
+
+	suffixSpans, err := findDotNetProperty(fileBytes, "VersionSuffix")
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", filePath, err)
+	}
+
+	versionFile := dotnetVersionFile{filePath: filePath, hasSuffix: len(suffixSpans) > 0}
+
+	var versionRaw string
+	switch {
+	case len(versionSpans) > 0:
+		versionRaw = versionSpans[0].text
+	case len(prefixSpans) > 0:
+		versionFile.usesPrefix = true
+		versionRaw = prefixSpans[0].text
+		if versionFile.hasSuffix && suffixSpans[0].text != "" {
+			versionRaw += "-" + suffixSpans[0].text
+		}
+	default:
+		return nil
+	}
+
+	// Versions which refer to other properties, e.g. $(MyVersion), get updated along with the property.
+	if strings.Contains(versionRaw, "$(") {
+		return nil
+	}
+
+	version, err := semver.NewVersion(versionRaw)
+	if err != nil {
+		return fmt.Errorf("invalid semver version %s in %s", versionRaw, filePath)
+	}
+
+	if len(p.versionFiles) > 0 && !version.Equal(&p.version) {
+		return fmt.Errorf(
+			"version %s in %s doesn't match version %s in %s",
+			versionRaw,
+			filePath,
+			p.version.String(),
+			p.versionFiles[0].filePath,
+		)
+	}
+
+	p.version = *version
+	p.versionFiles = append(p.versionFiles, versionFile)
+
+	return nil
+}
+
+func findDotNetProperty(fileBytes []byte, name string) ([]xmlTextSpan, error) {
+	return findXMLElementText(fileBytes, "Project", "PropertyGroup", name)
+}
+
+func (p *DotNetPackager) Name() string {
+	return "dotnet"
+}
+
+func (p *DotNetPackager) Version() string {
+	return fmt.Sprintf("v%s", p.version.String())
+}
+
+func (p *DotNetPackager) PackageFilePaths() []string {
+	filePaths := make([]string, 0, len(p.versionFiles))
+	for _, versionFile := range p.versionFiles {
+		filePaths = append(filePaths, versionFile.filePath)
+	}
+
+	return filePaths
+}
+
+func (p *DotNetPackager) BumpVersion(newVersion string) error {
+	version, err := semver.NewVersion(newVersion)
+	if err != nil {
+		return fmt.Errorf("invalid version %s: %w", newVersion, err)
+	}
+
+	for _, versionFile := range p.versionFiles {
+		if err := p.bumpVersionFile(versionFile, version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *DotNetPackager) bumpVersionFile(versionFile dotnetVersionFile, version *semver.Version) error {
+	fileBytes, err := p.store.ReadFile(versionFile.filePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", versionFile.filePath, err)
+	}
+
+	releaseVersion := fmt.Sprintf("%d.%d.%d", version.Major(), version.Minor(), version.Patch())
+
+	// Assembly and file versions are purely numeric, so they don't include the pre-release.
+	properties := map[string]string{
+		"AssemblyVersion": releaseVersion,
+		"FileVersion":     releaseVersion,
+	}
+
+	if versionFile.usesPrefix {
+		if version.Prerelease() != "" && !versionFile.hasSuffix {
+			return fmt.Errorf(
+				"%s needs a <VersionSuffix> alongside <VersionPrefix> for pre-release versions",
+				versionFile.filePath,
+			)
+		}
+
+		properties["VersionPrefix"] = releaseVersion
+		properties["VersionSuffix"] = version.Prerelease()
+	} else {
+		properties["Version"] = version.String()
+	}
+
+	for name, value := range properties {
+		spans, err := findDotNetProperty(fileBytes, name)
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %w", versionFile.filePath, err)
+		}
+
+		// Leave references to other properties alone.
+		spans = slices.DeleteFunc(spans, func(span xmlTextSpan) bool { return strings.Contains(span.text, "$(") })
+
+		if (name == "AssemblyVersion" || name == "FileVersion") && len(spans) > 0 {
+			value = padDotNetVersion(value, spans[0].text)
+		}
+
+		fileBytes = replaceXMLText(fileBytes, spans, value)
+	}
+
+	if err := p.store.WriteFile(versionFile.filePath, fileBytes); err != nil {
+		return fmt.Errorf("error writing %s: %w", versionFile.filePath, err)
+	}
+
+	return nil
+}
+
+// padDotNetVersion adds zero components to the version so that it has as many components as the existing
+// version, e.g. 1.2.3 becomes 1.2.3.0 if the existing version is 1.2.2.0.
+func padDotNetVersion(version string, existingVersion string) string {
+	for strings.Count(version, ".") < strings.Count(existingVersion, ".") {
+		version += ".0"
+	}
+
+	return version
+}
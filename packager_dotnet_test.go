@@ -0,0 +1,216 @@
+package main
+
+import (
+	"errors"
+	"path"
+	"slices"
+	"testing"
+)
+
+func TestDotNetBumpVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		newVersion  string
+		wantVersion string
+		want        map[string]string
+	}{
+		{
+			name: "version",
+			files: map[string]string{
+				"Demo.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <Version>1.2.3</Version>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Newtonsoft.Json" Version="1.2.3" />
+  </ItemGroup>
+</Project>
+`,
+			},
+			newVersion:  "v1.3.0-rc.1",
+			wantVersion: "v1.2.3",
+			want: map[string]string{
+				"Demo.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <Version>1.3.0-rc.1</Version>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Newtonsoft.Json" Version="1.2.3" />
+  </ItemGroup>
+</Project>
+`,
+			},
+		},
+		{
+			name: "version prefix and suffix",
+			files: map[string]string{
+				"Directory.Build.props": "<Project>\r\n  <PropertyGroup>\r\n    <VersionPrefix>1.2.3</VersionPrefix>\r\n" +
+					"    <VersionSuffix>beta.2</VersionSuffix>\r\n  </PropertyGroup>\r\n</Project>\r\n",
+			},
+			newVersion:  "v1.2.3",
+			wantVersion: "v1.2.3-beta.2",
+			want: map[string]string{
+				"Directory.Build.props": "<Project>\r\n  <PropertyGroup>\r\n    <VersionPrefix>1.2.3</VersionPrefix>\r\n" +
+					"    <VersionSuffix></VersionSuffix>\r\n  </PropertyGroup>\r\n</Project>\r\n",
+			},
+		},
+		{
+			name: "four part assembly and file versions",
+			files: map[string]string{
+				"Directory.Build.props": `<Project>
+  <PropertyGroup>
+    <Version>1.2.3</Version>
+    <AssemblyVersion>1.2.3.0</AssemblyVersion>
+    <FileVersion>1.2.3</FileVersion>
+    <InformationalVersion>$(Version)</InformationalVersion>
+  </PropertyGroup>
+</Project>
+`,
+				"src/Demo/Demo.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <Version>$(SharedVersion)</Version>
+  </PropertyGroup>
+</Project>
+`,
+			},
+			newVersion:  "v2.0.0-rc.1",
+			wantVersion: "v1.2.3",
+			want: map[string]string{
+				"Directory.Build.props": `<Project>
+  <PropertyGroup>
+    <Version>2.0.0-rc.1</Version>
+    <AssemblyVersion>2.0.0.0</AssemblyVersion>
+    <FileVersion>2.0.0</FileVersion>
+    <InformationalVersion>$(Version)</InformationalVersion>
+  </PropertyGroup>
+</Project>
+`,
+				"src/Demo/Demo.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <Version>$(SharedVersion)</Version>
+  </PropertyGroup>
+</Project>
+`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			for filePath, contents := range tt.files {
+				writeTestFile(t, path.Join(projectPath, filePath), contents)
+			}
+
+			packager := &DotNetPackager{store: &DiskFileStore{}}
+			if err := packager.Parse(projectPath); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if got := packager.Version(); got != tt.wantVersion {
+				t.Errorf("Version() = %s, want %s", got, tt.wantVersion)
+			}
+
+			if err := packager.BumpVersion(tt.newVersion); err != nil {
+				t.Fatalf("BumpVersion() error = %v", err)
+			}
+
+			for filePath, contents := range tt.want {
+				assertFileContents(t, path.Join(projectPath, filePath), contents)
+			}
+		})
+	}
+}
+
+func TestDotNetPrereleaseWithoutVersionSuffix(t *testing.T) {
+	projectPath := t.TempDir()
+
+	props := "<Project>\n  <PropertyGroup>\n    <VersionPrefix>1.2.3</VersionPrefix>\n  </PropertyGroup>\n</Project>\n"
+	writeTestFile(t, path.Join(projectPath, "Directory.Build.props"), props)
+
+	packager := &DotNetPackager{store: &DiskFileStore{}}
+	if err := packager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if err := packager.BumpVersion("v1.3.0"); err != nil {
+		t.Fatalf("BumpVersion() error = %v", err)
+	}
+
+	// There's nowhere to put the pre-release, so the file is left as it was.
+	if err := packager.BumpVersion("v1.4.0-rc.1"); err == nil {
+		t.Error("BumpVersion() succeeded for a pre-release without <VersionSuffix>, want error")
+	}
+
+	assertFileContents(
+		t,
+		path.Join(projectPath, "Directory.Build.props"),
+		"<Project>\n  <PropertyGroup>\n    <VersionPrefix>1.3.0</VersionPrefix>\n  </PropertyGroup>\n</Project>\n",
+	)
+}
+
+func TestDotNetProjectFiles(t *testing.T) {
+	projectPath := t.TempDir()
+
+	files := map[string]string{
+		"src/Api/Api.csproj":           "<Project><PropertyGroup><Version>1.2.3</Version></PropertyGroup></Project>",
+		"src/Core/Core.csproj":         "<Project><PropertyGroup><Version>1.2.3</Version></PropertyGroup></Project>",
+		"src/Tools/Tools.csproj":       "<Project><PropertyGroup><OutputType>Exe</OutputType></PropertyGroup></Project>",
+		"src/Api/bin/Debug/Api.csproj": "<Project><PropertyGroup><Version>0.0.1</Version></PropertyGroup></Project>",
+		"src/Api/obj/Api.csproj":       "<Project><PropertyGroup><Version>0.0.1</Version></PropertyGroup></Project>",
+		"node_modules/x/x.csproj":      "<Project><PropertyGroup><Version>0.0.1</Version></PropertyGroup></Project>",
+		"a/b/c/d/Deep.csproj":          "<Project><PropertyGroup><Version>0.0.1</Version></PropertyGroup></Project>",
+	}
+	for filePath, contents := range files {
+		writeTestFile(t, path.Join(projectPath, filePath), contents)
+	}
+
+	packager := &DotNetPackager{store: &DiskFileStore{}}
+	if err := packager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	wantPaths := []string{path.Join(projectPath, "src/Api/Api.csproj"), path.Join(projectPath, "src/Core/Core.csproj")}
+	if got := packager.PackageFilePaths(); !slices.Equal(got, wantPaths) {
+		t.Errorf("PackageFilePaths() = %v, want %v", got, wantPaths)
+	}
+}
+
+func TestDotNetParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			"mismatched versions",
+			map[string]string{
+				"Directory.Build.props": "<Project><PropertyGroup><Version>1.2.3</Version></PropertyGroup></Project>",
+				"Demo/Demo.csproj":      "<Project><PropertyGroup><Version>1.2.4</Version></PropertyGroup></Project>",
+			},
+		},
+		{"no version", map[string]string{"Demo.csproj": "<Project><PropertyGroup /></Project>"}},
+		{"invalid version", map[string]string{"Demo.csproj": "<Project><PropertyGroup><Version>latest</Version></PropertyGroup></Project>"}},
+		{"invalid XML", map[string]string{"Demo.csproj": "<Project><PropertyGroup><Version>1.2.3</PropertyGroup></Project>"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			for filePath, contents := range tt.files {
+				writeTestFile(t, path.Join(projectPath, filePath), contents)
+			}
+
+			packager := &DotNetPackager{store: &DiskFileStore{}}
+			if err := packager.Parse(projectPath); err == nil {
+				t.Error("Parse() succeeded, want error")
+			}
+		})
+	}
+
+	if err := (&DotNetPackager{store: &DiskFileStore{}}).Parse(t.TempDir()); !errors.Is(err, ErrPackageNotFound) {
+		t.Errorf("Parse() error = %v, want ErrPackageNotFound", err)
+	}
+}
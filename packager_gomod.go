@@ -1,13 +1,40 @@
 package main
 
-import "path"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/Masterminds/semver"
+	"github.com/rs/zerolog/log"
+)
+
+var goModuleRe = regexp.MustCompile(`(?m)^(module[ \t]+"?)([^"\s]+)("?)`)
+var goMajorVersionSuffixRe = regexp.MustCompile(`/v([0-9]+)$`)
+
+var goSkipDirs = []string{".git", "vendor", "node_modules", "testdata"}
+
+// GoModPackager doesn't have a version to bump as Go modules are versioned by their tags, but for v2 and above the
+// major version needs to be at the end of the module path, e.g. example.com/mod/v2. For major bumps, the module path
+// is updated in go.mod along with the imports of the module's own packages.
 type GoModPackager struct {
+	store           FileStore
+	projectPath     string
 	packageFilePath string
-}
+	modulePath      string
+	tagVersion      string
 
-// Some packages do put the version in the package URL, but we're not going to worry about parsing
-// and bump that at the moment, so these are all a no-op.
+	changedFilePaths []string
+}
 
 func (p *GoModPackager) Parse(projectPath string) error {
 	p.packageFilePath = path.Join(projectPath, "go.mod")
@@ -16,6 +43,20 @@ func (p *GoModPackager) Parse(projectPath string) error {
 		return ErrPackageNotFound
 	}
 
+	goModBytes, err := p.store.ReadFile(p.packageFilePath)
+	if err != nil {
+		return fmt.Errorf("error reading go.mod: %w", err)
+	}
+
+	matches := goModuleRe.FindSubmatch(goModBytes)
+	if matches == nil {
+		return errors.New("module directive not found in go.mod")
+	}
+
+	p.projectPath = projectPath
+	p.modulePath = string(matches[2])
+	p.changedFilePaths = nil
+
 	return nil
 }
 
@@ -27,10 +68,198 @@ func (p *GoModPackager) Version() string {
 	return ""
 }
 
-func (p *GoModPackager) PackageFilePath() string {
-	return p.packageFilePath
+func (p *GoModPackager) PackageFilePaths() []string {
+	return append([]string{p.packageFilePath}, p.changedFilePaths...)
 }
 
+// SetTagVersion sets the version being bumped from, as go.mod doesn't contain it.
+func (p *GoModPackager) SetTagVersion(version string) {
+	p.tagVersion = version
+}
+
+// BumpVersion updates the module path for major bumps to v2 or above. If the module path doesn't match the version
+// being bumped from, it's left for the user to fix rather than guessing.
 func (p *GoModPackager) BumpVersion(newVersion string) error {
+	version, err := semver.NewVersion(newVersion)
+	if err != nil {
+		return fmt.Errorf("invalid version %s: %w", newVersion, err)
+	}
+
+	if strings.HasPrefix(p.modulePath, "gopkg.in/") {
+		log.Warn().Msgf("Module path %s uses gopkg.in versioning - not updating it", p.modulePath)
+		return nil
+	}
+
+	basePath, pathMajor := splitGoModulePath(p.modulePath)
+
+	newMajor := max(version.Major(), 1)
+	if newMajor == pathMajor {
+		return nil
+	}
+
+	tagVersion, err := semver.NewVersion(p.tagVersion)
+	if err != nil {
+		return fmt.Errorf("invalid version %s being bumped from: %w", p.tagVersion, err)
+	}
+
+	// Only a major bump moves the module path on, e.g. a minor bump from v2.0.0 to v2.1.0 of example.com/mod means
+	// the module path was already wrong.
+	if newMajor == max(tagVersion.Major(), 1) {
+		return fmt.Errorf("module path %s doesn't match the major version of %s", p.modulePath, p.tagVersion)
+	}
+
+	// Anything other than going up one major version means the module path was already wrong before the bump.
+	if newMajor != pathMajor+1 {
+		return fmt.Errorf("module path %s doesn't match the major version of %s", p.modulePath, newVersion)
+	}
+
+	newModulePath := fmt.Sprintf("%s/v%d", basePath, newMajor)
+
+	log.Debug().Msgf("Updating module path from %s to %s", p.modulePath, newModulePath)
+
+	goModBytes, err := p.store.ReadFile(p.packageFilePath)
+	if err != nil {
+		return fmt.Errorf("error reading go.mod: %w", err)
+	}
+
+	goModBytes = goModuleRe.ReplaceAll(goModBytes, []byte("${1}"+escapeReplacement(newModulePath)+"${3}"))
+	if err := p.store.WriteFile(p.packageFilePath, goModBytes); err != nil {
+		return fmt.Errorf("error writing go.mod: %w", err)
+	}
+
+	if err := p.rewriteImports(p.modulePath, newModulePath); err != nil {
+		return err
+	}
+
+	p.modulePath = newModulePath
+	return nil
+}
+
+// splitGoModulePath splits the major version suffix off the module path, returning major version 1 if there isn't
+// one.
+func splitGoModulePath(modulePath string) (string, int64) {
+	matches := goMajorVersionSuffixRe.FindStringSubmatch(modulePath)
+	if matches == nil {
+		return modulePath, 1
+	}
+
+	major, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil || major < 2 {
+		return modulePath, 1
+	}
+
+	return strings.TrimSuffix(modulePath, matches[0]), major
+}
+
+// rewriteImports updates the imports of the module's own packages in all the .go files in the module. Nested
+// modules are skipped, along with any imports of their packages, as they have their own module path and version.
+func (p *GoModPackager) rewriteImports(oldModulePath string, newModulePath string) error {
+	var goFilePaths []string
+	var nestedModulePaths []string
+
+	err := filepath.WalkDir(p.projectPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if filePath == p.projectPath {
+				return nil
+			}
+
+			if slices.Contains(goSkipDirs, entry.Name()) {
+				return filepath.SkipDir
+			}
+
+			if fileExists(path.Join(filePath, "go.mod")) {
+				relPath, err := filepath.Rel(p.projectPath, filePath)
+				if err != nil {
+					return err
+				}
+
+				nestedModulePaths = append(nestedModulePaths, oldModulePath+"/"+filepath.ToSlash(relPath))
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if strings.HasSuffix(entry.Name(), ".go") {
+			goFilePaths = append(goFilePaths, filePath)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error updating imports: %w", err)
+	}
+
+	rewriteImport := func(importPath string) (string, bool) {
+		if importPath != oldModulePath && !strings.HasPrefix(importPath, oldModulePath+"/") {
+			return "", false
+		}
+
+		for _, nestedModulePath := range nestedModulePaths {
+			if importPath == nestedModulePath || strings.HasPrefix(importPath, nestedModulePath+"/") {
+				return "", false
+			}
+		}
+
+		return newModulePath + strings.TrimPrefix(importPath, oldModulePath), true
+	}
+
+	for _, filePath := range goFilePaths {
+		if err := p.rewriteFileImports(filePath, rewriteImport); err != nil {
+			return fmt.Errorf("error updating imports: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// rewriteFileImports replaces the paths of the file's import specs using rewriteImport, leaving the rest of the
+// file untouched.
+func (p *GoModPackager) rewriteFileImports(filePath string, rewriteImport func(string) (string, bool)) error {
+	fileBytes, err := p.store.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filePath, err)
+	}
+
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, filePath, fileBytes, parser.ImportsOnly)
+	if err != nil {
+		log.Warn().Msgf("Unable to parse %s, so not updating its imports: %v", filePath, err)
+		return nil
+	}
+
+	newFileBytes := fileBytes
+
+	// Replace from the end of the file so that the offsets of the earlier imports stay the same.
+	for _, importSpec := range slices.Backward(file.Imports) {
+		importPath, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		newImportPath, ok := rewriteImport(importPath)
+		if !ok {
+			continue
+		}
+
+		quote := importSpec.Path.Value[:1]
+		start := fileSet.Position(importSpec.Path.Pos()).Offset
+		end := fileSet.Position(importSpec.Path.End()).Offset
+		newFileBytes = slices.Concat(newFileBytes[:start], []byte(quote+newImportPath+quote), newFileBytes[end:])
+	}
+
+	if bytes.Equal(fileBytes, newFileBytes) {
+		return nil
+	}
+
+	if err := p.store.WriteFile(filePath, newFileBytes); err != nil {
+		return fmt.Errorf("error writing %s: %w", filePath, err)
+	}
+
+	p.changedFilePaths = append(p.changedFilePaths, filePath)
 	return nil
 }
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"path"
+	"slices"
+	"testing"
+)
+
+func TestGoModBumpVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		goMod      string
+		tagVersion string
+		newVersion string
+		wantGoMod  string
+		wantErr    bool
+	}{
+		{"minor bump", "module example.com/mod\n", "v1.2.3", "v1.3.0", "module example.com/mod\n", false},
+		{"v2", "module example.com/mod\n\ngo 1.23\n", "v1.2.3", "v2.0.0", "module example.com/mod/v2\n\ngo 1.23\n", false},
+		{"v2 pre-release", "module example.com/mod\n", "v1.2.3", "v2.0.0-rc.1", "module example.com/mod/v2\n", false},
+		{"v2 release", "module example.com/mod/v2\n", "v2.0.0-rc.1", "v2.0.0", "module example.com/mod/v2\n", false},
+		{"v3", "module example.com/mod/v2\n", "v2.1.0", "v3.0.0", "module example.com/mod/v3\n", false},
+		{"quoted", "module \"example.com/mod\"\n", "v1.2.3", "v2.0.0", "module \"example.com/mod/v2\"\n", false},
+		{"gopkg.in", "module gopkg.in/mod.v1\n", "v1.2.3", "v2.0.0", "module gopkg.in/mod.v1\n", false},
+		{"already wrong", "module example.com/mod\n", "v2.1.0", "v3.0.0", "module example.com/mod\n", true},
+		{"minor bump of wrong path", "module example.com/mod\n", "v2.0.0", "v2.1.0", "module example.com/mod\n", true},
+		{"patch bump of wrong path", "module example.com/mod/v2\n", "v3.0.0", "v3.0.1", "module example.com/mod/v2\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			writeTestFile(t, path.Join(projectPath, "go.mod"), tt.goMod)
+
+			packager := &GoModPackager{store: &DiskFileStore{}}
+			if err := packager.Parse(projectPath); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			packager.SetTagVersion(tt.tagVersion)
+			err := packager.BumpVersion(tt.newVersion)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BumpVersion() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			got, err := os.ReadFile(path.Join(projectPath, "go.mod"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.wantGoMod {
+				t.Errorf("go.mod = %q, want %q", got, tt.wantGoMod)
+			}
+		})
+	}
+}
+
+func TestGoModRewriteImports(t *testing.T) {
+	projectPath := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":          "module example.com/mod\n",
+		"tools/go.mod":    "module example.com/mod/tools\n",
+		"tools/tools.go":  "package tools\n\nimport _ \"example.com/mod/internal\"\n",
+		"vendor/x/x.go":   "package x\n\nimport _ \"example.com/mod/internal\"\n",
+		"testdata/bad.go": "package bad\n\nimport \"example.com/mod/internal\"\n",
+		"broken.go":       "package main\n\nimport (\n\t\"example.com/mod/internal\"\n",
+		"main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/mod"
+	internal "example.com/mod/internal"
+	"example.com/mod/tools"
+	"example.com/mod/tools/lint"
+	"example.com/modx"
+)
+
+import ` + "`example.com/mod/pkg`" + `
+
+// See "example.com/mod/internal" for details.
+const docs = "https://example.com/mod/internal"
+
+func main() {
+	fmt.Println("example.com/mod")
+}
+`,
+	}
+	for filePath, contents := range files {
+		writeTestFile(t, path.Join(projectPath, filePath), contents)
+	}
+
+	packager := &GoModPackager{store: &DiskFileStore{}}
+	if err := packager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	packager.SetTagVersion("v1.2.3")
+	if err := packager.BumpVersion("v2.0.0"); err != nil {
+		t.Fatalf("BumpVersion() error = %v", err)
+	}
+
+	want := map[string]string{
+		"go.mod":          "module example.com/mod/v2\n",
+		"tools/go.mod":    files["tools/go.mod"],
+		"tools/tools.go":  files["tools/tools.go"],
+		"vendor/x/x.go":   files["vendor/x/x.go"],
+		"testdata/bad.go": files["testdata/bad.go"],
+		"broken.go":       files["broken.go"],
+		"main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/mod/v2"
+	internal "example.com/mod/v2/internal"
+	"example.com/mod/tools"
+	"example.com/mod/tools/lint"
+	"example.com/modx"
+)
+
+import ` + "`example.com/mod/v2/pkg`" + `
+
+// See "example.com/mod/internal" for details.
+const docs = "https://example.com/mod/internal"
+
+func main() {
+	fmt.Println("example.com/mod")
+}
+`,
+	}
+	for filePath, wantContents := range want {
+		got, err := os.ReadFile(path.Join(projectPath, filePath))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != wantContents {
+			t.Errorf("%s = %q, want %q", filePath, got, wantContents)
+		}
+	}
+
+	wantChanged := []string{path.Join(projectPath, "go.mod"), path.Join(projectPath, "main.go")}
+	if got := packager.PackageFilePaths(); !slices.Equal(got, wantChanged) {
+		t.Errorf("PackageFilePaths() = %v, want %v", got, wantChanged)
+	}
+
+	// Parsing again mustn't keep the files changed by the previous bump.
+	if err := packager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got, want := packager.PackageFilePaths(), wantChanged[:1]; !slices.Equal(got, want) {
+		t.Errorf("PackageFilePaths() after Parse() = %v, want %v", got, want)
+	}
+}
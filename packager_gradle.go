@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// The version regexes all have three groups: everything before the version, the version and everything after it.
+var gradlePropertiesVersionRe = regexp.MustCompile(`(?m)^(\s*version\s*[=:][ \t]*)(\S+)([ \t\r]*)$`)
+var gradleBuildVersionRe = regexp.MustCompile(`(?m)^(\s*version\s*(?:=\s*)?["'])([^"']+)(["'])`)
+var gradleVersionNameRe = regexp.MustCompile(`(?m)^(\s*versionName\s*(?:=\s*)?["'])([^"']+)(["'])`)
+var gradleVersionCodeRe = regexp.MustCompile(`(?m)^(\s*versionCode\s*(?:=\s*)?)(\d+)()`)
+
+// Matches the project paths in `include ':app', ':lib'` or `include(":app")` in the settings file.
+var gradleIncludeRe = regexp.MustCompile(`(?m)^\s*include\s*\(?(.*)$`)
+var gradleProjectPathRe = regexp.MustCompile(`["']:?([^"']+)["']`)
+
+var gradleBuildFileNames = []string{"build.gradle.kts", "build.gradle"}
+var gradleSettingsFileNames = []string{"settings.gradle.kts", "settings.gradle"}
+
+// gradleVersionLocation is a file which the version is set in, and the regex to find it.
+type gradleVersionLocation struct {
+	filePath string
+	re       *regexp.Regexp
+}
+
+// GradlePackager bumps the project version in gradle.properties or the build scripts, along with the versionName
+// of any Android modules. If enabled, the Android versionCode is also incremented.
+type GradlePackager struct {
+	store                FileStore
+	incrementVersionCode bool
+
+	versionLocations     []gradleVersionLocation
+	versionCodeFilePaths []string
+	version              semver.Version
+}
+
+func (p *GradlePackager) Parse(projectPath string) error {
+	rootBuildFilePath := p.findBuildFile(projectPath)
+	propertiesFilePath := path.Join(projectPath, "gradle.properties")
+
+	if rootBuildFilePath == "" {
+		return ErrPackageNotFound
+	}
+
+	p.versionLocations = nil
+	p.versionCodeFilePaths = nil
+
+	if fileExists(propertiesFilePath) {
+		if err := p.addVersionLocation(propertiesFilePath, gradlePropertiesVersionRe); err != nil {
+			return err
+		}
+	}
+
+	buildFilePaths := []string{rootBuildFilePath}
+	for _, modulePath := range p.findModules(projectPath) {
+		if buildFilePath := p.findBuildFile(path.Join(projectPath, modulePath)); buildFilePath != "" {
+			buildFilePaths = append(buildFilePaths, buildFilePath)
+		}
+	}
+
+	for _, buildFilePath := range buildFilePaths {
+		for _, re := range []*regexp.Regexp{gradleBuildVersionRe, gradleVersionNameRe} {
+			if err := p.addVersionLocation(buildFilePath, re); err != nil {
+				return err
+			}
+		}
+
+		if p.incrementVersionCode {
+			buildBytes, err := p.store.ReadFile(buildFilePath)
+			if err != nil {
+				return fmt.Errorf("error reading %s: %w", buildFilePath, err)
+			}
+
+			if gradleVersionCodeRe.Match(buildBytes) {
+				p.versionCodeFilePaths = append(p.versionCodeFilePaths, buildFilePath)
+			}
+		}
+	}
+
+	if len(p.versionLocations) == 0 {
+		return errors.New("version not found in gradle.properties or build scripts")
+	}
+
+	return nil
+}
+
+// addVersionLocation adds the file as a version location if the regex matches a literal version, making sure it
+// agrees with any versions already found.
+func (p *GradlePackager) addVersionLocation(filePath string, re *regexp.Regexp) error {
+	fileBytes, err := p.store.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filePath, err)
+	}
+
+	indexes := findGradleVersion(fileBytes, re)
+	if indexes == nil {
+		return nil
+	}
+
+	versionRaw := string(fileBytes[indexes[4]:indexes[5]])
+
+	version, err := semver.NewVersion(versionRaw)
+	if err != nil {
+		return fmt.Errorf("invalid semver version %s in %s", versionRaw, filePath)
+	}
+
+	if len(p.versionLocations) > 0 && !version.Equal(&p.version) {
+		return fmt.Errorf(
+			"version %s in %s doesn't match version %s in %s",
+			versionRaw,
+			filePath,
+			p.version.String(),
+			p.versionLocations[0].filePath,
+		)
+	}
+
+	p.version = *version
+	p.versionLocations = append(p.versionLocations, gradleVersionLocation{filePath: filePath, re: re})
+	return nil
+}
+
+// findGradleVersion returns the submatch indexes of the first match of the regex with a literal version, or nil if
+// there isn't one. Versions which refer to other properties, e.g. "$version", get updated along with the property
+// so are skipped. Only this match is checked when parsing, so it's the only one which gets bumped - any later
+// matches are likely to be the versions of something else.
+func findGradleVersion(fileBytes []byte, re *regexp.Regexp) []int {
+	for _, indexes := range re.FindAllSubmatchIndex(fileBytes, -1) {
+		if !bytes.Contains(fileBytes[indexes[4]:indexes[5]], []byte("$")) {
+			return indexes
+		}
+	}
+
+	return nil
+}
+
+func (p *GradlePackager) findBuildFile(dirPath string) string {
+	for _, fileName := range gradleBuildFileNames {
+		if filePath := path.Join(dirPath, fileName); fileExists(filePath) {
+			return filePath
+		}
+	}
+
+	return ""
+}
+
+// findModules returns the directories of the projects included in the settings file, e.g. "app" for ":app".
+func (p *GradlePackager) findModules(projectPath string) []string {
+	var modulePaths []string
+
+	for _, fileName := range gradleSettingsFileNames {
+		settingsBytes, err := p.store.ReadFile(path.Join(projectPath, fileName))
+		if err != nil {
+			continue
+		}
+
+		for _, includeMatches := range gradleIncludeRe.FindAllStringSubmatch(string(settingsBytes), -1) {
+			for _, projectMatches := range gradleProjectPathRe.FindAllStringSubmatch(includeMatches[1], -1) {
+				modulePath := strings.ReplaceAll(projectMatches[1], ":", "/")
+				if !slices.Contains(modulePaths, modulePath) {
+					modulePaths = append(modulePaths, modulePath)
+				}
+			}
+		}
+
+		break
+	}
+
+	return modulePaths
+}
+
+func (p *GradlePackager) Name() string {
+	return "gradle"
+}
+
+func (p *GradlePackager) Version() string {
+	return fmt.Sprintf("v%s", p.version.String())
+}
+
+func (p *GradlePackager) PackageFilePaths() []string {
+	var filePaths []string
+	for _, location := range p.versionLocations {
+		if !slices.Contains(filePaths, location.filePath) {
+			filePaths = append(filePaths, location.filePath)
+		}
+	}
+
+	for _, filePath := range p.versionCodeFilePaths {
+		if !slices.Contains(filePaths, filePath) {
+			filePaths = append(filePaths, filePath)
+		}
+	}
+
+	return filePaths
+}
+
+func (p *GradlePackager) BumpVersion(newVersion string) error {
+	newVersion = strings.TrimPrefix(newVersion, "v")
+
+	for _, location := range p.versionLocations {
+		fileBytes, err := p.store.ReadFile(location.filePath)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", location.filePath, err)
+		}
+
+		indexes := findGradleVersion(fileBytes, location.re)
+		if indexes == nil {
+			return fmt.Errorf("version not found in %s", location.filePath)
+		}
+
+		fileBytes = slices.Concat(fileBytes[:indexes[4]], []byte(newVersion), fileBytes[indexes[5]:])
+		if err := p.store.WriteFile(location.filePath, fileBytes); err != nil {
+			return fmt.Errorf("error writing %s: %w", location.filePath, err)
+		}
+	}
+
+	// Only the first versionCode is incremented, in the same way as the version - any others are likely to be for
+	// flavors or build types which set their own.
+	for _, filePath := range p.versionCodeFilePaths {
+		fileBytes, err := p.store.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", filePath, err)
+		}
+
+		indexes := findGradleVersion(fileBytes, gradleVersionCodeRe)
+		if indexes == nil {
+			return fmt.Errorf("versionCode not found in %s", filePath)
+		}
+
+		versionCode := string(fileBytes[indexes[4]:indexes[5]])
+		code, err := strconv.Atoi(versionCode)
+		if err != nil {
+			return fmt.Errorf("invalid versionCode %s: %w", versionCode, err)
+		}
+
+		fileBytes = slices.Concat(fileBytes[:indexes[4]], []byte(strconv.Itoa(code+1)), fileBytes[indexes[5]:])
+		if err := p.store.WriteFile(filePath, fileBytes); err != nil {
+			return fmt.Errorf("error writing %s: %w", filePath, err)
+		}
+	}
+
+	return nil
+}
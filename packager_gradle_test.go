@@ -0,0 +1,144 @@
+package main
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestGradleBumpVersion(t *testing.T) {
+	tests := []struct {
+		name                 string
+		files                map[string]string
+		incrementVersionCode bool
+		wantVersion          string
+		want                 map[string]string
+	}{
+		{
+			name: "gradle.properties",
+			files: map[string]string{
+				"gradle.properties": "group=com.example\nversion=1.2.3\n",
+				"build.gradle":      "version = project.version\n",
+			},
+			wantVersion: "v1.2.3",
+			want: map[string]string{
+				"gradle.properties": "group=com.example\nversion=1.3.0\n",
+				"build.gradle":      "version = project.version\n",
+			},
+		},
+		{
+			name: "unchecked version lines are left alone",
+			files: map[string]string{
+				"build.gradle.kts": "version = \"1.2.3\"\n\nconfigure<JacocoPluginExtension> {\n    version = \"0.8.12\"\n}\n",
+			},
+			wantVersion: "v1.2.3",
+			want: map[string]string{
+				"build.gradle.kts": "version = \"1.3.0\"\n\nconfigure<JacocoPluginExtension> {\n    version = \"0.8.12\"\n}\n",
+			},
+		},
+		{
+			name: "property references are skipped",
+			files: map[string]string{
+				"build.gradle": "version \"$projectVersion\"\nversion '1.2.3'\n",
+			},
+			wantVersion: "v1.2.3",
+			want: map[string]string{
+				"build.gradle": "version \"$projectVersion\"\nversion '1.3.0'\n",
+			},
+		},
+		{
+			name: "android module",
+			files: map[string]string{
+				"gradle.properties": "version=1.2.3\r\n",
+				"build.gradle":      "plugins {}\n",
+				"settings.gradle":   "include ':app'\n",
+				"app/build.gradle":  "android {\n    versionCode 41\n    versionName \"1.2.3\"\n}\n",
+				"lib/build.gradle":  "version = \"9.9.9\"\n",
+			},
+			incrementVersionCode: true,
+			wantVersion:          "v1.2.3",
+			want: map[string]string{
+				"gradle.properties": "version=1.3.0\r\n",
+				"app/build.gradle":  "android {\n    versionCode 42\n    versionName \"1.3.0\"\n}\n",
+				"lib/build.gradle":  "version = \"9.9.9\"\n",
+			},
+		},
+		{
+			name: "only the default versionCode is incremented",
+			files: map[string]string{
+				"build.gradle.kts": "android {\n    defaultConfig {\n        versionCode = 41\n        versionName = \"1.2.3\"\n    }\n" +
+					"    productFlavors {\n        create(\"pro\") {\n            versionCode = 1041\n        }\n    }\n}\n",
+			},
+			incrementVersionCode: true,
+			wantVersion:          "v1.2.3",
+			want: map[string]string{
+				"build.gradle.kts": "android {\n    defaultConfig {\n        versionCode = 42\n        versionName = \"1.3.0\"\n    }\n" +
+					"    productFlavors {\n        create(\"pro\") {\n            versionCode = 1041\n        }\n    }\n}\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			for filePath, contents := range tt.files {
+				writeTestFile(t, path.Join(projectPath, filePath), contents)
+			}
+
+			packager := &GradlePackager{store: &DiskFileStore{}, incrementVersionCode: tt.incrementVersionCode}
+			if err := packager.Parse(projectPath); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if got := packager.Version(); got != tt.wantVersion {
+				t.Errorf("Version() = %s, want %s", got, tt.wantVersion)
+			}
+
+			if err := packager.BumpVersion("v1.3.0"); err != nil {
+				t.Fatalf("BumpVersion() error = %v", err)
+			}
+
+			for filePath, want := range tt.want {
+				got, err := os.ReadFile(path.Join(projectPath, filePath))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(got) != want {
+					t.Errorf("%s = %q, want %q", filePath, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGradleParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"no version", map[string]string{"build.gradle": "plugins {}\n"}},
+		{"invalid version", map[string]string{"build.gradle": "version = 'latest'\n"}},
+		{
+			name: "mismatched versions",
+			files: map[string]string{
+				"gradle.properties": "version=1.2.3\n",
+				"build.gradle":      "version = '1.2.4'\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			for filePath, contents := range tt.files {
+				writeTestFile(t, path.Join(projectPath, filePath), contents)
+			}
+
+			packager := &GradlePackager{store: &DiskFileStore{}}
+			if err := packager.Parse(projectPath); err == nil {
+				t.Errorf("Parse() succeeded, want error")
+			}
+		})
+	}
+}
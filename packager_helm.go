@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// Matches top-level `version: 1.2.3` and `appVersion: "v1.2.3"` lines. The groups are everything before the
+// version, the version and everything after it, so that quotes and comments are kept as they are.
+var helmVersionRe = regexp.MustCompile(`(?m)^(version:[ \t]*["']?)([^"'\s#]+)(.*)$`)
+var helmAppVersionRe = regexp.MustCompile(`(?m)^(appVersion:[ \t]*["']?)([^"'\s#]+)(.*)$`)
+
+// HelmPackager bumps the chart version in Chart.yaml, either in the project root or for each of the charts in the
+// charts/ directory. If enabled, appVersion is also kept in sync with the application version. The file is edited
+// line by line so that comments and ordering are preserved.
+type HelmPackager struct {
+	store          FileStore
+	syncAppVersion bool
+
+	chartFilePaths []string
+	version        semver.Version
+	appVersion     string
+}
+
+func (p *HelmPackager) Parse(projectPath string) error {
+	chartFilePaths := []string{path.Join(projectPath, "Chart.yaml")}
+	if !fileExists(chartFilePaths[0]) {
+		var err error
+		chartFilePaths, err = filepath.Glob(path.Join(projectPath, "charts", "*", "Chart.yaml"))
+		if err != nil {
+			return fmt.Errorf("error finding charts: %w", err)
+		}
+	}
+
+	if len(chartFilePaths) == 0 {
+		return ErrPackageNotFound
+	}
+
+	p.chartFilePaths = nil
+	p.appVersion = ""
+	for _, chartFilePath := range chartFilePaths {
+		chartBytes, err := p.store.ReadFile(chartFilePath)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", chartFilePath, err)
+		}
+
+		matches := helmVersionRe.FindSubmatch(chartBytes)
+		if matches == nil {
+			return fmt.Errorf("version not found in %s", chartFilePath)
+		}
+
+		version, err := semver.NewVersion(string(matches[2]))
+		if err != nil {
+			return errors.New("invalid semver version")
+		}
+
+		if len(p.chartFilePaths) > 0 && !version.Equal(&p.version) {
+			return fmt.Errorf(
+				"version %s in %s doesn't match version %s in %s",
+				matches[2],
+				chartFilePath,
+				p.version.String(),
+				p.chartFilePaths[0],
+			)
+		}
+
+		if err := p.parseAppVersion(chartFilePath, chartBytes); err != nil {
+			return err
+		}
+
+		p.version = *version
+		p.chartFilePaths = append(p.chartFilePaths, chartFilePath)
+	}
+
+	return nil
+}
+
+// parseAppVersion records the chart's appVersion if it's being kept in sync. App versions which aren't semver versions,
+// e.g. "latest", are ignored.
+func (p *HelmPackager) parseAppVersion(chartFilePath string, chartBytes []byte) error {
+	if !p.syncAppVersion {
+		return nil
+	}
+
+	matches := helmAppVersionRe.FindSubmatch(chartBytes)
+	if matches == nil {
+		return nil
+	}
+
+	version, err := semver.NewVersion(string(matches[2]))
+	if err != nil {
+		return nil
+	}
+
+	appVersion := fmt.Sprintf("v%s", version.String())
+	if p.appVersion != "" && appVersion != p.appVersion {
+		return fmt.Errorf(
+			"appVersion %s in %s doesn't match appVersion %s in the other charts",
+			matches[2],
+			chartFilePath,
+			p.appVersion,
+		)
+	}
+
+	p.appVersion = appVersion
+	return nil
+}
+
+func (p *HelmPackager) Name() string {
+	return "helm"
+}
+
+func (p *HelmPackager) Version() string {
+	return fmt.Sprintf("v%s", p.version.String())
+}
+
+func (p *HelmPackager) PackageFilePaths() []string {
+	return p.chartFilePaths
+}
+
+func (p *HelmPackager) AppVersion() string {
+	return p.appVersion
+}
+
+func (p *HelmPackager) BumpVersion(newVersion string) error {
+	newVersion = strings.TrimPrefix(newVersion, "v")
+	return p.bumpCharts(newVersion, newVersion)
+}
+
+// BumpAppVersion is used when the chart has its own version, separate from the application's. The chart version gets
+// the same kind of bump as the application, e.g. a minor bump of the application from v1.2.3 to v1.3.0 takes the
+// chart from 0.1.0 to 0.2.0. Anything less than a minor bump, including moving between pre-releases, is a patch bump
+// of the chart.
+func (p *HelmPackager) BumpAppVersion(oldVersion string, newVersion string) error {
+	oldAppVersion, err := semver.NewVersion(oldVersion)
+	if err != nil {
+		return fmt.Errorf("invalid semver version %s: %w", oldVersion, err)
+	}
+
+	newAppVersion, err := semver.NewVersion(newVersion)
+	if err != nil {
+		return fmt.Errorf("invalid semver version %s: %w", newVersion, err)
+	}
+
+	var chartVersion semver.Version
+	switch {
+	case newAppVersion.Major() != oldAppVersion.Major():
+		chartVersion = p.version.IncMajor()
+	case newAppVersion.Minor() != oldAppVersion.Minor():
+		chartVersion = p.version.IncMinor()
+	default:
+		chartVersion = p.version.IncPatch()
+	}
+
+	return p.bumpCharts(chartVersion.String(), newAppVersion.String())
+}
+
+// bumpCharts sets the version, and the appVersion if it's being kept in sync, in each of the charts.
+func (p *HelmPackager) bumpCharts(chartVersion string, appVersion string) error {
+	for _, chartFilePath := range p.chartFilePaths {
+		chartBytes, err := p.store.ReadFile(chartFilePath)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", chartFilePath, err)
+		}
+
+		// Chart versions never have a 'v' prefix.
+		chartBytes = helmVersionRe.ReplaceAll(chartBytes, []byte("${1}"+chartVersion+"${3}"))
+
+		if p.syncAppVersion {
+			chartBytes = helmAppVersionRe.ReplaceAllFunc(chartBytes, func(match []byte) []byte {
+				matches := helmAppVersionRe.FindSubmatch(match)
+
+				// App versions are often image tags, so keep the 'v' prefix if there was one.
+				if strings.HasPrefix(string(matches[2]), "v") {
+					return []byte(string(matches[1]) + "v" + appVersion + string(matches[3]))
+				}
+
+				return []byte(string(matches[1]) + appVersion + string(matches[3]))
+			})
+		}
+
+		if err := p.store.WriteFile(chartFilePath, chartBytes); err != nil {
+			return fmt.Errorf("error writing %s: %w", chartFilePath, err)
+		}
+	}
+
+	return nil
+}
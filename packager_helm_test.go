@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"path"
+	"slices"
+	"testing"
+)
+
+func TestHelmBumpVersion(t *testing.T) {
+	chart := `apiVersion: v2
+name: demo
+# The chart version
+version: "1.2.3" # bumped by bumper
+appVersion: v1.2.3
+dependencies:
+  - name: redis
+    version: 1.2.3
+    repository: https://charts.example.com
+`
+
+	tests := []struct {
+		name           string
+		chart          string
+		syncAppVersion bool
+		want           string
+	}{
+		{
+			name:  "chart version only",
+			chart: chart,
+			want: `apiVersion: v2
+name: demo
+# The chart version
+version: "1.3.0" # bumped by bumper
+appVersion: v1.2.3
+dependencies:
+  - name: redis
+    version: 1.2.3
+    repository: https://charts.example.com
+`,
+		},
+		{
+			name:           "app version with v prefix",
+			chart:          chart,
+			syncAppVersion: true,
+			want: `apiVersion: v2
+name: demo
+# The chart version
+version: "1.3.0" # bumped by bumper
+appVersion: v1.3.0
+dependencies:
+  - name: redis
+    version: 1.2.3
+    repository: https://charts.example.com
+`,
+		},
+		{
+			name:           "app version without v prefix",
+			chart:          "name: demo\r\nversion: 1.2.3\r\nappVersion: '1.2.3'\r\n",
+			syncAppVersion: true,
+			want:           "name: demo\r\nversion: 1.3.0\r\nappVersion: '1.3.0'\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			chartFilePath := path.Join(projectPath, "Chart.yaml")
+			writeTestFile(t, chartFilePath, tt.chart)
+
+			packager := &HelmPackager{store: &DiskFileStore{}, syncAppVersion: tt.syncAppVersion}
+			if err := packager.Parse(projectPath); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if got := packager.Version(); got != "v1.2.3" {
+				t.Errorf("Version() = %s, want v1.2.3", got)
+			}
+
+			if err := packager.BumpVersion("v1.3.0"); err != nil {
+				t.Fatalf("BumpVersion() error = %v", err)
+			}
+
+			assertFileContents(t, chartFilePath, tt.want)
+		})
+	}
+}
+
+func TestHelmChartsDirectory(t *testing.T) {
+	projectPath := t.TempDir()
+	writeTestFile(t, path.Join(projectPath, "charts/api/Chart.yaml"), "name: api\nversion: 0.4.0\n")
+	writeTestFile(t, path.Join(projectPath, "charts/web/Chart.yaml"), "name: web\nversion: 0.4.0\n")
+
+	packager := &HelmPackager{store: &DiskFileStore{}}
+	if err := packager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	wantPaths := []string{
+		path.Join(projectPath, "charts/api/Chart.yaml"),
+		path.Join(projectPath, "charts/web/Chart.yaml"),
+	}
+	if got := packager.PackageFilePaths(); !slices.Equal(got, wantPaths) {
+		t.Errorf("PackageFilePaths() = %v, want %v", got, wantPaths)
+	}
+
+	if err := packager.BumpVersion("v0.5.0"); err != nil {
+		t.Fatalf("BumpVersion() error = %v", err)
+	}
+
+	assertFileContents(t, wantPaths[0], "name: api\nversion: 0.5.0\n")
+	assertFileContents(t, wantPaths[1], "name: web\nversion: 0.5.0\n")
+
+	writeTestFile(t, wantPaths[1], "name: web\nversion: 0.6.0\n")
+	if err := packager.Parse(projectPath); err == nil {
+		t.Error("Parse() succeeded with mismatched chart versions, want error")
+	}
+}
+
+func TestHelmParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		chart string
+	}{
+		{"no version", "name: demo\n"},
+		{"only a dependency version", "name: demo\ndependencies:\n  - version: 1.0.0\n"},
+		{"invalid version", "name: demo\nversion: latest\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			writeTestFile(t, path.Join(projectPath, "Chart.yaml"), tt.chart)
+
+			packager := &HelmPackager{store: &DiskFileStore{}}
+			if err := packager.Parse(projectPath); err == nil {
+				t.Error("Parse() succeeded, want error")
+			}
+		})
+	}
+
+	if err := (&HelmPackager{store: &DiskFileStore{}}).Parse(t.TempDir()); !errors.Is(err, ErrPackageNotFound) {
+		t.Errorf("Parse() error = %v, want ErrPackageNotFound", err)
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+const mavenSnapshotSuffix = "-SNAPSHOT"
+
+// Matches CI-friendly versions like ${revision}, where the version is actually set in the properties.
+var mavenPropertyRe = regexp.MustCompile(`^\$\{([A-Za-z0-9_.-]+)\}$`)
+
+type mavenProject struct {
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Parent     struct {
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+	} `xml:"parent"`
+	Modules []string `xml:"modules>module"`
+}
+
+// MavenPackager bumps the version in pom.xml, along with any modules in a multi-module build which reference it.
+// The XML is edited in place so that the formatting is preserved.
+type MavenPackager struct {
+	store           FileStore
+	packageFilePath string
+
+	// The element path that the version is actually set at, which is normally project/version but could be a
+	// property for CI-friendly versions.
+	versionPath []string
+	rawVersion  string
+	version     semver.Version
+
+	// Poms for the modules (and their modules), and the artifact IDs of every pom in the build.
+	modulePaths []string
+	artifactIDs []string
+
+	changedFilePaths []string
+}
+
+func (p *MavenPackager) Parse(projectPath string) error {
+	packageFilePath := path.Join(projectPath, "pom.xml")
+
+	if !fileExists(packageFilePath) {
+		return ErrPackageNotFound
+	}
+
+	project, err := p.readPom(packageFilePath)
+	if err != nil {
+		return err
+	}
+
+	// The version is inherited from the parent if not set, in which case it's the parent which needs bumping.
+	if project.Version == "" {
+		return errors.New("version not found in pom.xml")
+	}
+
+	versionPath := []string{"project", "version"}
+	rawVersion := strings.TrimSpace(project.Version)
+
+	if matches := mavenPropertyRe.FindStringSubmatch(rawVersion); matches != nil {
+		versionPath = []string{"project", "properties", matches[1]}
+
+		pomBytes, err := p.store.ReadFile(packageFilePath)
+		if err != nil {
+			return fmt.Errorf("error reading pom.xml: %w", err)
+		}
+
+		spans, err := findXMLElementText(pomBytes, versionPath...)
+		if err != nil || len(spans) == 0 {
+			return fmt.Errorf("property %s not found in pom.xml", matches[1])
+		}
+
+		rawVersion = spans[0].text
+	}
+
+	version, err := semver.NewVersion(rawVersion)
+	if err != nil {
+		return errors.New("invalid semver version")
+	}
+
+	p.packageFilePath = packageFilePath
+	p.versionPath = versionPath
+	p.rawVersion = rawVersion
+	p.version = *version
+	p.modulePaths = nil
+	p.artifactIDs = []string{project.ArtifactID}
+
+	return p.parseModules(projectPath, project)
+}
+
+// parseModules finds the poms of all the modules in the build, recursing into modules which have their own
+// modules.
+func (p *MavenPackager) parseModules(projectPath string, project *mavenProject) error {
+	for _, module := range project.Modules {
+		modulePath := path.Join(projectPath, module)
+
+		modulePomPath := modulePath
+		if !strings.HasSuffix(modulePomPath, ".xml") {
+			modulePomPath = path.Join(modulePath, "pom.xml")
+		}
+
+		if !fileExists(modulePomPath) || slices.Contains(p.modulePaths, modulePomPath) {
+			continue
+		}
+
+		moduleProject, err := p.readPom(modulePomPath)
+		if err != nil {
+			return err
+		}
+
+		p.modulePaths = append(p.modulePaths, modulePomPath)
+		p.artifactIDs = append(p.artifactIDs, moduleProject.ArtifactID)
+
+		if err := p.parseModules(path.Dir(modulePomPath), moduleProject); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *MavenPackager) readPom(pomPath string) (*mavenProject, error) {
+	pomBytes, err := p.store.ReadFile(pomPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", pomPath, err)
+	}
+
+	var project mavenProject
+	if err := xml.Unmarshal(pomBytes, &project); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", pomPath, err)
+	}
+
+	return &project, nil
+}
+
+func (p *MavenPackager) Name() string {
+	return "maven"
+}
+
+func (p *MavenPackager) Version() string {
+	// Snapshot versions are the upcoming version rather than the last release, so there's nothing to compare
+	// against the latest tag.
+	if p.isSnapshot() {
+		return ""
+	}
+
+	return fmt.Sprintf("v%s", p.version.String())
+}
+
+func (p *MavenPackager) isSnapshot() bool {
+	return strings.HasSuffix(p.rawVersion, mavenSnapshotSuffix)
+}
+
+func (p *MavenPackager) PackageFilePaths() []string {
+	return append([]string{p.packageFilePath}, p.changedFilePaths...)
+}
+
+// BumpVersion sets the version in pom.xml, along with the parent version of any modules which reference it and
+// the version of any modules which share the same version. A release version replaces any -SNAPSHOT version.
+func (p *MavenPackager) BumpVersion(newVersion string) error {
+	newVersion = strings.TrimPrefix(newVersion, "v")
+
+	pomBytes, err := p.store.ReadFile(p.packageFilePath)
+	if err != nil {
+		return fmt.Errorf("error reading pom.xml: %w", err)
+	}
+
+	spans, err := findXMLElementText(pomBytes, p.versionPath...)
+	if err != nil {
+		return fmt.Errorf("error parsing pom.xml: %w", err)
+	} else if len(spans) == 0 {
+		return errors.New("version not found in pom.xml")
+	}
+
+	if err := p.store.WriteFile(p.packageFilePath, replaceXMLText(pomBytes, spans[:1], newVersion)); err != nil {
+		return fmt.Errorf("error writing pom.xml: %w", err)
+	}
+
+	for _, modulePath := range p.modulePaths {
+		if err := p.bumpModule(modulePath, p.rawVersion, newVersion); err != nil {
+			return err
+		}
+	}
+
+	version, err := semver.NewVersion(newVersion)
+	if err != nil {
+		return fmt.Errorf("invalid version %s: %w", newVersion, err)
+	}
+
+	p.rawVersion = newVersion
+	p.version = *version
+
+	return nil
+}
+
+func (p *MavenPackager) bumpModule(modulePath string, oldVersion string, newVersion string) error {
+	pomBytes, err := p.store.ReadFile(modulePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", modulePath, err)
+	}
+
+	project, err := p.readPom(modulePath)
+	if err != nil {
+		return err
+	}
+
+	var spans []xmlTextSpan
+
+	// Only touch the parent version if the parent is part of this build, rather than e.g. spring-boot-starter-parent.
+	if strings.TrimSpace(project.Parent.Version) == oldVersion && slices.Contains(p.artifactIDs, project.Parent.ArtifactID) {
+		parentSpans, err := findXMLElementText(pomBytes, "project", "parent", "version")
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %w", modulePath, err)
+		}
+
+		spans = append(spans, parentSpans...)
+	}
+
+	if strings.TrimSpace(project.Version) == oldVersion {
+		versionSpans, err := findXMLElementText(pomBytes, "project", "version")
+		if err != nil {
+			return fmt.Errorf("error parsing %s: %w", modulePath, err)
+		}
+
+		spans = append(spans, versionSpans...)
+	}
+
+	if len(spans) == 0 {
+		return nil
+	}
+
+	slices.SortFunc(spans, func(a, b xmlTextSpan) int { return a.start - b.start })
+
+	if err := p.store.WriteFile(modulePath, replaceXMLText(pomBytes, spans, newVersion)); err != nil {
+		return fmt.Errorf("error writing %s: %w", modulePath, err)
+	}
+
+	if !slices.Contains(p.changedFilePaths, modulePath) {
+		p.changedFilePaths = append(p.changedFilePaths, modulePath)
+	}
+
+	return nil
+}
+
+// DevelopmentVersion returns the -SNAPSHOT version of the next patch version, which is what the Maven release
+// plugin uses by default. After a pre-release, it's the snapshot of the version being pre-released.
+func (p *MavenPackager) DevelopmentVersion(releasedVersion string) (string, error) {
+	version, err := semver.NewVersion(releasedVersion)
+	if err != nil {
+		return "", fmt.Errorf("invalid version %s: %w", releasedVersion, err)
+	}
+
+	// IncPatch on a pre-release just drops the pre-release, which is what we want.
+	nextVersion := version.IncPatch()
+
+	return fmt.Sprintf("v%s%s", nextVersion.String(), mavenSnapshotSuffix), nil
+}
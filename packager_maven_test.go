@@ -0,0 +1,233 @@
+package main
+
+import (
+	"path"
+	"slices"
+	"testing"
+)
+
+func TestMavenBumpMultiModule(t *testing.T) {
+	projectPath := t.TempDir()
+
+	files := map[string]string{
+		"pom.xml": `<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+    <version>1.2.0-SNAPSHOT</version>
+  </parent>
+  <artifactId>demo-parent</artifactId>
+  <version>1.2.0-SNAPSHOT</version>
+  <modules>
+    <module>core</module>
+    <module>app</module>
+  </modules>
+  <dependencies>
+    <dependency>
+      <artifactId>junit</artifactId>
+      <version>1.2.0-SNAPSHOT</version>
+    </dependency>
+  </dependencies>
+</project>
+`,
+		"core/pom.xml": `<project>
+  <parent>
+    <artifactId>demo-parent</artifactId>
+    <version>1.2.0-SNAPSHOT</version>
+  </parent>
+  <artifactId>demo-core</artifactId>
+</project>
+`,
+		"app/pom.xml": `<project>
+  <parent>
+    <artifactId>demo-parent</artifactId>
+    <version>1.2.0-SNAPSHOT</version>
+  </parent>
+  <artifactId>demo-app</artifactId>
+  <version>1.2.0-SNAPSHOT</version>
+  <modules>
+    <module>plugin/pom.xml</module>
+  </modules>
+</project>
+`,
+		"app/plugin/pom.xml": `<project>
+  <parent>
+    <artifactId>demo-app</artifactId>
+    <version>1.2.0-SNAPSHOT</version>
+  </parent>
+  <artifactId>demo-plugin</artifactId>
+  <version>0.1.0</version>
+</project>
+`,
+	}
+	for filePath, contents := range files {
+		writeTestFile(t, path.Join(projectPath, filePath), contents)
+	}
+
+	packager := &MavenPackager{store: &DiskFileStore{}}
+	if err := packager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// Snapshots are the upcoming version, so there's no released version to check against the tags.
+	if got := packager.Version(); got != "" {
+		t.Errorf("Version() = %q, want empty", got)
+	}
+
+	if err := packager.BumpVersion("v1.2.0"); err != nil {
+		t.Fatalf("BumpVersion() error = %v", err)
+	}
+
+	if got := packager.Version(); got != "v1.2.0" {
+		t.Errorf("Version() after bump = %q, want v1.2.0", got)
+	}
+
+	want := map[string]string{
+		"pom.xml": `<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+    <version>1.2.0-SNAPSHOT</version>
+  </parent>
+  <artifactId>demo-parent</artifactId>
+  <version>1.2.0</version>
+  <modules>
+    <module>core</module>
+    <module>app</module>
+  </modules>
+  <dependencies>
+    <dependency>
+      <artifactId>junit</artifactId>
+      <version>1.2.0-SNAPSHOT</version>
+    </dependency>
+  </dependencies>
+</project>
+`,
+		"core/pom.xml": `<project>
+  <parent>
+    <artifactId>demo-parent</artifactId>
+    <version>1.2.0</version>
+  </parent>
+  <artifactId>demo-core</artifactId>
+</project>
+`,
+		"app/pom.xml": `<project>
+  <parent>
+    <artifactId>demo-parent</artifactId>
+    <version>1.2.0</version>
+  </parent>
+  <artifactId>demo-app</artifactId>
+  <version>1.2.0</version>
+  <modules>
+    <module>plugin/pom.xml</module>
+  </modules>
+</project>
+`,
+		"app/plugin/pom.xml": `<project>
+  <parent>
+    <artifactId>demo-app</artifactId>
+    <version>1.2.0</version>
+  </parent>
+  <artifactId>demo-plugin</artifactId>
+  <version>0.1.0</version>
+</project>
+`,
+	}
+	for filePath, contents := range want {
+		assertFileContents(t, path.Join(projectPath, filePath), contents)
+	}
+
+	wantPaths := []string{
+		path.Join(projectPath, "pom.xml"),
+		path.Join(projectPath, "core/pom.xml"),
+		path.Join(projectPath, "app/pom.xml"),
+		path.Join(projectPath, "app/plugin/pom.xml"),
+	}
+	if got := packager.PackageFilePaths(); !slices.Equal(got, wantPaths) {
+		t.Errorf("PackageFilePaths() = %v, want %v", got, wantPaths)
+	}
+}
+
+func TestMavenBumpCIFriendlyVersion(t *testing.T) {
+	projectPath := t.TempDir()
+
+	pom := `<project>
+  <artifactId>demo</artifactId>
+  <version>${revision}</version>
+  <properties>
+    <java.version>21</java.version>
+    <revision>1.2.3</revision>
+  </properties>
+</project>
+`
+	writeTestFile(t, path.Join(projectPath, "pom.xml"), pom)
+
+	packager := &MavenPackager{store: &DiskFileStore{}}
+	if err := packager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := packager.Version(); got != "v1.2.3" {
+		t.Errorf("Version() = %q, want v1.2.3", got)
+	}
+
+	if err := packager.BumpVersion("v1.3.0"); err != nil {
+		t.Fatalf("BumpVersion() error = %v", err)
+	}
+
+	want := `<project>
+  <artifactId>demo</artifactId>
+  <version>${revision}</version>
+  <properties>
+    <java.version>21</java.version>
+    <revision>1.3.0</revision>
+  </properties>
+</project>
+`
+	assertFileContents(t, path.Join(projectPath, "pom.xml"), want)
+}
+
+func TestMavenParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		pom  string
+	}{
+		{"inherited version", "<project><parent><version>1.0.0</version></parent></project>"},
+		{"missing property", "<project><version>${revision}</version></project>"},
+		{"invalid version", "<project><version>latest</version></project>"},
+		{"invalid XML", "<project><version>1.0.0</project>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			writeTestFile(t, path.Join(projectPath, "pom.xml"), tt.pom)
+
+			packager := &MavenPackager{store: &DiskFileStore{}}
+			if err := packager.Parse(projectPath); err == nil {
+				t.Error("Parse() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestMavenDevelopmentVersion(t *testing.T) {
+	tests := []struct {
+		releasedVersion string
+		want            string
+	}{
+		{"v1.2.0", "v1.2.1-SNAPSHOT"},
+		{"v2.0.0-rc.1", "v2.0.0-SNAPSHOT"},
+	}
+
+	for _, tt := range tests {
+		got, err := (&MavenPackager{}).DevelopmentVersion(tt.releasedVersion)
+		if err != nil {
+			t.Fatalf("DevelopmentVersion(%s) error = %v", tt.releasedVersion, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("DevelopmentVersion(%s) = %s, want %s", tt.releasedVersion, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MultiPackager bumps the version for each of several packagers which apply to the same project.
+type MultiPackager struct {
+	packagers []Packager
+	version   string
+}
+
+// NewMultiPackager combines the already parsed packagers, making sure that they all agree on the current version.
+// Packagers without a version (e.g. go mod) are left out of the check. Packagers with their own version as well as the
+// application's (e.g. Helm charts) are checked on the application's version, so that a chart at 0.1.0 can package an
+// application at 1.2.3.
+func NewMultiPackager(packagers []Packager) (*MultiPackager, error) {
+	version := ""
+	for _, packager := range packagers {
+		packagerVersion := agreedVersion(packager)
+		if packagerVersion == "" {
+			continue
+		}
+
+		if version == "" {
+			version = packagerVersion
+		} else if !sameVersion(packagerVersion, version) {
+			return nil, fmt.Errorf("package versions don't match: %s", describePackagerVersions(packagers))
+		}
+	}
+
+	// If nothing else has a version, e.g. a Helm chart for a Go module, the chart version is the project's version.
+	if version == "" {
+		for _, packager := range packagers {
+			if packager.Version() != "" {
+				version = packager.Version()
+				break
+			}
+		}
+	}
+
+	return &MultiPackager{packagers: packagers, version: version}, nil
+}
+
+// agreedVersion returns the version of the packager which has to match the other packagers' versions.
+func agreedVersion(packager Packager) string {
+	if appVersioner, ok := packager.(AppVersioner); ok {
+		return appVersioner.AppVersion()
+	}
+
+	return packager.Version()
+}
+
+func describePackagerVersions(packagers []Packager) string {
+	var versions []string
+	for _, packager := range packagers {
+		version := agreedVersion(packager)
+		if version == "" {
+			continue
+		}
+
+		if _, ok := packager.(AppVersioner); ok {
+			versions = append(versions, fmt.Sprintf("%s app %s", packager.Name(), version))
+		} else {
+			versions = append(versions, fmt.Sprintf("%s %s", packager.Name(), version))
+		}
+	}
+
+	return strings.Join(versions, ", ")
+}
+
+// Parse isn't needed as the packagers have already been parsed.
+func (p *MultiPackager) Parse(_ string) error {
+	return nil
+}
+
+func (p *MultiPackager) Name() string {
+	names := make([]string, 0, len(p.packagers))
+	for _, packager := range p.packagers {
+		names = append(names, packager.Name())
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func (p *MultiPackager) Version() string {
+	return p.version
+}
+
+func (p *MultiPackager) PackageFilePaths() []string {
+	var filePaths []string
+	for _, packager := range p.packagers {
+		for _, filePath := range packager.PackageFilePaths() {
+			if !slices.Contains(filePaths, filePath) {
+				filePaths = append(filePaths, filePath)
+			}
+		}
+	}
+
+	return filePaths
+}
+
+func (p *MultiPackager) BumpVersion(newVersion string) error {
+	for _, packager := range p.packagers {
+		var err error
+		if appVersioner, ok := packager.(AppVersioner); ok && !sameVersion(packager.Version(), p.version) {
+			// The packager has its own version, so it's bumped alongside the application rather than set to match it.
+			err = appVersioner.BumpAppVersion(p.version, newVersion)
+		} else {
+			err = packager.BumpVersion(newVersion)
+		}
+
+		if err != nil {
+			return fmt.Errorf("error bumping %s version: %w", packager.Name(), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakePackager is an already parsed packager at a fixed version, which records the versions it's bumped to.
+type fakePackager struct {
+	name     string
+	version  string
+	bumpedTo []string
+}
+
+func (f *fakePackager) Parse(_ string) error {
+	return nil
+}
+
+func (f *fakePackager) Name() string {
+	return f.name
+}
+
+func (f *fakePackager) Version() string {
+	return f.version
+}
+
+func (f *fakePackager) PackageFilePaths() []string {
+	return []string{f.name + ".json"}
+}
+
+func (f *fakePackager) BumpVersion(newVersion string) error {
+	f.bumpedTo = append(f.bumpedTo, newVersion)
+	return nil
+}
+
+func TestNewMultiPackager(t *testing.T) {
+	tests := []struct {
+		name        string
+		versions    []string
+		wantVersion string
+		wantErr     string
+	}{
+		{"versions agree", []string{"v1.2.3", "v1.2.3"}, "v1.2.3", ""},
+		{"versions don't agree", []string{"v1.2.3", "v1.2.4"}, "", "package versions don't match: a v1.2.3, b v1.2.4"},
+		{"build metadata is ignored", []string{"v1.2.0+post.1", "v1.2.0"}, "v1.2.0+post.1", ""},
+		{"empty version is ignored", []string{"", "v1.2.3", "v1.2.3"}, "v1.2.3", ""},
+		{"all versions empty", []string{"", ""}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var packagers []Packager
+			for i, version := range tt.versions {
+				packagers = append(packagers, &fakePackager{name: string(rune('a' + i)), version: version})
+			}
+
+			multiPackager, err := NewMultiPackager(packagers)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("NewMultiPackager() error = %v, want error containing %q", err, tt.wantErr)
+				}
+
+				return
+			} else if err != nil {
+				t.Fatalf("NewMultiPackager() error = %v", err)
+			}
+
+			if got := multiPackager.Version(); got != tt.wantVersion {
+				t.Errorf("Version() = %q, want %q", got, tt.wantVersion)
+			}
+
+			if err := multiPackager.BumpVersion("v1.3.0"); err != nil {
+				t.Fatalf("BumpVersion() error = %v", err)
+			}
+
+			// Packagers without a version are still bumped.
+			for _, packager := range packagers {
+				if bumpedTo := packager.(*fakePackager).bumpedTo; !slices.Equal(bumpedTo, []string{"v1.3.0"}) {
+					t.Errorf("%s bumped to %v, want [v1.3.0]", packager.Name(), bumpedTo)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiPackagerHelmChart(t *testing.T) {
+	tests := []struct {
+		name           string
+		chart          string
+		syncAppVersion bool
+		newVersion     string
+		wantChart      string
+	}{
+		{
+			name:           "major bump",
+			chart:          "name: demo\nversion: 0.1.0\nappVersion: \"1.2.3\"\n",
+			syncAppVersion: true,
+			newVersion:     "v2.0.0",
+			wantChart:      "name: demo\nversion: 1.0.0\nappVersion: \"2.0.0\"\n",
+		},
+		{
+			name:           "minor bump",
+			chart:          "name: demo\nversion: 0.1.0\nappVersion: \"1.2.3\"\n",
+			syncAppVersion: true,
+			newVersion:     "v1.3.0",
+			wantChart:      "name: demo\nversion: 0.2.0\nappVersion: \"1.3.0\"\n",
+		},
+		{
+			name:           "pre-release",
+			chart:          "name: demo\nversion: 0.1.0\nappVersion: v1.2.3\n",
+			syncAppVersion: true,
+			newVersion:     "v1.2.4-rc.1",
+			wantChart:      "name: demo\nversion: 0.1.1\nappVersion: v1.2.4-rc.1\n",
+		},
+		{
+			name:       "app version not synced",
+			chart:      "name: demo\nversion: 0.1.0\nappVersion: latest\n",
+			newVersion: "v1.3.0",
+			wantChart:  "name: demo\nversion: 0.2.0\nappVersion: latest\n",
+		},
+		{
+			name:           "chart version matches",
+			chart:          "name: demo\nversion: 1.2.3\nappVersion: 1.2.3\n",
+			syncAppVersion: true,
+			newVersion:     "v1.3.0",
+			wantChart:      "name: demo\nversion: 1.3.0\nappVersion: 1.3.0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			chartFilePath := path.Join(projectPath, "Chart.yaml")
+			writeTestFile(t, chartFilePath, tt.chart)
+
+			helmPackager := &HelmPackager{store: &DiskFileStore{}, syncAppVersion: tt.syncAppVersion}
+			if err := helmPackager.Parse(projectPath); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			appPackager := &fakePackager{name: "npm", version: "v1.2.3"}
+			multiPackager, err := NewMultiPackager([]Packager{appPackager, helmPackager})
+			if err != nil {
+				t.Fatalf("NewMultiPackager() error = %v", err)
+			}
+
+			if got := multiPackager.Version(); got != "v1.2.3" {
+				t.Errorf("Version() = %s, want v1.2.3", got)
+			}
+
+			if err := multiPackager.BumpVersion(tt.newVersion); err != nil {
+				t.Fatalf("BumpVersion() error = %v", err)
+			}
+
+			if !slices.Equal(appPackager.bumpedTo, []string{tt.newVersion}) {
+				t.Errorf("npm bumped to %v, want [%s]", appPackager.bumpedTo, tt.newVersion)
+			}
+
+			assertFileContents(t, chartFilePath, tt.wantChart)
+		})
+	}
+}
+
+func TestMultiPackagerHelmAppVersionMismatch(t *testing.T) {
+	projectPath := t.TempDir()
+	writeTestFile(t, path.Join(projectPath, "Chart.yaml"), "name: demo\nversion: 0.1.0\nappVersion: 1.2.2\n")
+
+	helmPackager := &HelmPackager{store: &DiskFileStore{}, syncAppVersion: true}
+	if err := helmPackager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	_, err := NewMultiPackager([]Packager{&fakePackager{name: "npm", version: "v1.2.3"}, helmPackager})
+	if want := "package versions don't match: npm v1.2.3, helm app v1.2.2"; err == nil || err.Error() != want {
+		t.Errorf("NewMultiPackager() error = %v, want %q", err, want)
+	}
+}
+
+func TestMultiPackagerHelmChartOnlyVersion(t *testing.T) {
+	projectPath := t.TempDir()
+	writeTestFile(t, path.Join(projectPath, "Chart.yaml"), "name: demo\nversion: 0.1.0\n")
+
+	helmPackager := &HelmPackager{store: &DiskFileStore{}, syncAppVersion: true}
+	if err := helmPackager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// Nothing else has a version (e.g. a Go module), so the chart version is the project's version.
+	multiPackager, err := NewMultiPackager([]Packager{&fakePackager{name: "go mod"}, helmPackager})
+	if err != nil {
+		t.Fatalf("NewMultiPackager() error = %v", err)
+	}
+
+	if got := multiPackager.Version(); got != "v0.1.0" {
+		t.Errorf("Version() = %s, want v0.1.0", got)
+	}
+
+	if err := multiPackager.BumpVersion("v0.2.0"); err != nil {
+		t.Fatalf("BumpVersion() error = %v", err)
+	}
+
+	assertFileContents(t, path.Join(projectPath, "Chart.yaml"), "name: demo\nversion: 0.2.0\n")
+}
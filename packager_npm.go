@@ -1,24 +1,53 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"io/fs"
-	"os"
 	"path"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/Masterminds/semver"
 	"github.com/rs/zerolog/log"
 )
 
-var packageVersionRe = regexp.MustCompile(`(?m)^(\s+)"version"(\s*):(\s*)"[^"]+"(\s*,?)$`)
+var npmLockFileNames = []string{"package-lock.json", "npm-shrinkwrap.json"}
 
+var npmDependencyTypes = []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"}
+
+// Matches simple dependency ranges which refer to a single version, e.g. 1.2.3, ^1.2.3 or workspace:~1.2.3. The
+// groups are the prefix including any operator and the version.
+var npmRangeRe = regexp.MustCompile(`^((?:workspace:)?[\^~=]?)v?([0-9]+\.[0-9]+\.[0-9]+\S*)$`)
+
+var pnpmPackagesKeyRe = regexp.MustCompile(`^packages:\s*(#.*)?$`)
+var pnpmPackageGlobRe = regexp.MustCompile(`^\s+-\s*['"]?([^'"#]+?)['"]?\s*(#.*)?$`)
+
+// npmPackageJSON is the part of package.json needed to work out the version and workspaces.
+type npmPackageJSON struct {
+	Name       string          `json:"name"`
+	Version    string          `json:"version"`
+	Workspaces json.RawMessage `json:"workspaces"`
+}
+
+// NPMPackager bumps the version in package.json, along with package-lock.json if there is one. In npm, yarn or
+// pnpm workspaces, the workspace packages which have the same version as the root package are bumped too, and
+// dependencies between the packages are updated if they refer to the old version. The files are edited in place so
+// that the formatting is preserved.
 type NPMPackager struct {
+	store           FileStore
 	packageFilePath string
 	version         semver.Version
+
+	// Directories of the workspace packages, and the lock file which covers them if there is one.
+	workspaceDirs []string
+	lockFilePath  string
+
+	changedFilePaths []string
 }
 
 func (p *NPMPackager) Parse(projectPath string) error {
@@ -28,41 +57,169 @@ func (p *NPMPackager) Parse(projectPath string) error {
 		return ErrPackageNotFound
 	}
 
-	packageFile, err := os.Open(packageFilePath)
+	packageContents, err := p.readPackageJSON(packageFilePath)
+	if err != nil {
+		return err
+	}
+
+	if packageContents.Version == "" {
+		return errors.New("version not found in package.json")
+	}
+
+	packageVersion, err := semver.NewVersion(packageContents.Version)
+	if err != nil {
+		return errors.New("invalid semver version")
+	}
+
+	workspaceDirs, err := p.findWorkspaces(projectPath, packageContents)
 	if err != nil {
-		return fmt.Errorf("error opening package.json: %w", err)
+		return err
 	}
-	defer func(packageFile *os.File) {
-		if err := packageFile.Close(); err != nil {
-			log.Error().Err(err).Msg("error closing package.json")
+
+	p.packageFilePath = packageFilePath
+	p.version = *packageVersion
+	p.workspaceDirs = workspaceDirs
+	p.lockFilePath = p.findLockFile(projectPath)
+	p.changedFilePaths = nil
+
+	return nil
+}
+
+func (p *NPMPackager) readPackageJSON(packageFilePath string) (*npmPackageJSON, error) {
+	packageBytes, err := p.store.ReadFile(packageFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", packageFilePath, err)
+	}
+
+	var packageContents npmPackageJSON
+	if err := json.Unmarshal(packageBytes, &packageContents); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", packageFilePath, err)
+	}
+
+	return &packageContents, nil
+}
+
+// findWorkspaces finds the workspace package directories from the "workspaces" field of package.json, which is
+// either a list of globs (npm and yarn) or an object with a "packages" list (older yarn), or failing that from
+// pnpm-workspace.yaml.
+func (p *NPMPackager) findWorkspaces(projectPath string, packageContents *npmPackageJSON) ([]string, error) {
+	var globs []string
+
+	if len(packageContents.Workspaces) > 0 {
+		if err := json.Unmarshal(packageContents.Workspaces, &globs); err != nil {
+			var workspacesObject struct {
+				Packages []string `json:"packages"`
+			}
+
+			if err := json.Unmarshal(packageContents.Workspaces, &workspacesObject); err != nil {
+				return nil, errors.New("invalid workspaces in package.json")
+			}
+
+			globs = workspacesObject.Packages
+		}
+	} else if pnpmWorkspaceFilePath := path.Join(projectPath, "pnpm-workspace.yaml"); fileExists(pnpmWorkspaceFilePath) {
+		var err error
+		globs, err = p.readPnpmWorkspaceGlobs(pnpmWorkspaceFilePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var includeGlobs, excludeGlobs []string
+	for _, glob := range globs {
+		if excludeGlob, found := strings.CutPrefix(glob, "!"); found {
+			excludeGlobs = append(excludeGlobs, excludeGlob)
+		} else {
+			includeGlobs = append(includeGlobs, glob)
 		}
-	}(packageFile)
+	}
 
-	packageBytes, err := io.ReadAll(packageFile)
+	excludedDirs, err := globDirs(projectPath, excludeGlobs)
 	if err != nil {
-		return fmt.Errorf("error reading package.json: %w", err)
+		return nil, err
 	}
 
-	packageContents := make(map[string]interface{})
-	err = json.Unmarshal(packageBytes, &packageContents)
+	workspaceDirs, err := globDirs(projectPath, includeGlobs)
 	if err != nil {
-		return fmt.Errorf("error parsing package.json: %w", err)
+		return nil, err
 	}
 
-	packageVersionRaw, ok := packageContents["version"].(string)
-	if !ok {
-		return errors.New("version not found in package.json")
+	return slices.DeleteFunc(workspaceDirs, func(dir string) bool {
+		return dir == projectPath || slices.Contains(excludedDirs, dir) || !fileExists(path.Join(dir, "package.json"))
+	}), nil
+}
+
+// readPnpmWorkspaceGlobs reads the package globs from the "packages" list in pnpm-workspace.yaml. This only
+// supports the block list style which pnpm itself uses, rather than pulling in a full YAML parser.
+func (p *NPMPackager) readPnpmWorkspaceGlobs(filePath string) ([]string, error) {
+	fileBytes, err := p.store.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading pnpm-workspace.yaml: %w", err)
+	}
+
+	var globs []string
+	inPackages := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(fileBytes))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		switch {
+		case pnpmPackagesKeyRe.MatchString(line):
+			inPackages = true
+		case strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#"):
+		case !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "-"):
+			inPackages = false
+		case inPackages:
+			if matches := pnpmPackageGlobRe.FindStringSubmatch(line); matches != nil {
+				globs = append(globs, matches[1])
+			}
+		}
 	}
 
-	packageVersion, err := semver.NewVersion(packageVersionRaw)
+	return globs, nil
+}
+
+// globDirs finds the directories matching the globs relative to the project path.
+func globDirs(projectPath string, globs []string) ([]string, error) {
+	var dirs []string
+	for _, glob := range globs {
+		matches, err := filepath.Glob(path.Join(projectPath, strings.TrimSuffix(glob, "/")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid workspace glob %s: %w", glob, err)
+		}
+
+		for _, match := range matches {
+			if !slices.Contains(dirs, match) {
+				dirs = append(dirs, match)
+			}
+		}
+	}
+
+	return dirs, nil
+}
+
+// findLockFile looks for the lock file in the project directory, or in a parent directory if the project is a
+// package in a workspace, without going outside the repository.
+func (p *NPMPackager) findLockFile(projectPath string) string {
+	absPath, err := filepath.Abs(projectPath)
 	if err != nil {
-		return errors.New("invalid semver version")
+		return ""
 	}
 
-	p.packageFilePath = packageFilePath
-	p.version = *packageVersion
+	for dir := absPath; dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		for _, lockFileName := range npmLockFileNames {
+			if lockFilePath := path.Join(dir, lockFileName); fileExists(lockFilePath) {
+				return lockFilePath
+			}
+		}
 
-	return nil
+		if fileExists(path.Join(dir, ".git")) {
+			break
+		}
+	}
+
+	return ""
 }
 
 func (p *NPMPackager) Name() string {
@@ -73,55 +230,254 @@ func (p *NPMPackager) Version() string {
 	return fmt.Sprintf("v%s", p.version.String())
 }
 
-func (p *NPMPackager) PackageFilePath() string {
-	return p.packageFilePath
+func (p *NPMPackager) PackageFilePaths() []string {
+	filePaths := []string{p.packageFilePath}
+	for _, filePath := range p.changedFilePaths {
+		if !slices.Contains(filePaths, filePath) {
+			filePaths = append(filePaths, filePath)
+		}
+	}
+
+	return filePaths
 }
 
 func (p *NPMPackager) BumpVersion(newVersion string) error {
-	// When we actually do the bump, just use regex replace to prevent messing up the formatting, order, etc.
-	packageFile, err := os.Open(p.packageFilePath)
+	// We don't want the 'v' in the package.json version.
+	newVersion = strings.TrimPrefix(newVersion, "v")
+	oldVersion := p.version.String()
+
+	packageDirs := append([]string{path.Dir(p.packageFilePath)}, p.workspaceDirs...)
+
+	// Workspace packages with their own version are left alone.
+	var bumpedDirs []string
+	var bumpedNames []string
+	for _, packageDir := range packageDirs {
+		packageContents, err := p.readPackageJSON(path.Join(packageDir, "package.json"))
+		if err != nil {
+			return err
+		}
+
+		version, err := semver.NewVersion(packageContents.Version)
+		if err != nil || !version.Equal(&p.version) {
+			log.Debug().Msgf("Not bumping %s as it doesn't share the version %s", packageDir, oldVersion)
+			continue
+		}
+
+		bumpedDirs = append(bumpedDirs, packageDir)
+		if packageContents.Name != "" {
+			bumpedNames = append(bumpedNames, packageContents.Name)
+		}
+	}
+
+	var lockEdits []npmLockFileEdit
+	for _, packageDir := range packageDirs {
+		bumpVersion := slices.Contains(bumpedDirs, packageDir)
+
+		packageLockEdits, err := p.bumpPackageFile(packageDir, bumpVersion, bumpedNames, oldVersion, newVersion)
+		if err != nil {
+			return err
+		}
+
+		lockEdits = append(lockEdits, packageLockEdits...)
+	}
+
+	if p.lockFilePath != "" {
+		if err := p.updateLockFile(lockEdits); err != nil {
+			return err
+		}
+	}
+
+	version, err := semver.NewVersion(newVersion)
 	if err != nil {
-		return fmt.Errorf("error opening package.json: %w", err)
+		return fmt.Errorf("invalid version %s: %w", newVersion, err)
 	}
-	defer func(packageFile *os.File) {
-		if err := packageFile.Close(); err != nil && !errors.Is(err, fs.ErrClosed) {
-			log.Error().Err(err).Msg("error closing package.json")
+	p.version = *version
+
+	return nil
+}
+
+// npmLockFileEdit is a change to a package.json which needs making to the matching package in the lock file too.
+type npmLockFileEdit struct {
+	packageDir string
+	path       []string
+	value      string
+}
+
+// bumpPackageFile sets the version in a package.json if it's being bumped, and updates any dependencies on the
+// bumped packages. Returns the changes which need making to the lock file to match.
+func (p *NPMPackager) bumpPackageFile(
+	packageDir string,
+	bumpVersion bool,
+	bumpedNames []string,
+	oldVersion string,
+	newVersion string,
+) ([]npmLockFileEdit, error) {
+	packageFilePath := path.Join(packageDir, "package.json")
+
+	packageBytes, err := p.store.ReadFile(packageFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", packageFilePath, err)
+	}
+
+	strs, err := findJSONStrings(packageBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", packageFilePath, err)
+	}
+
+	var edits []jsonString
+	var lockEdits []npmLockFileEdit
+
+	if bumpVersion {
+		if version := findJSONString(strs, "version"); version != nil {
+			version.value = newVersion
+			edits = append(edits, *version)
+			lockEdits = append(lockEdits, npmLockFileEdit{packageDir: packageDir, path: version.path, value: newVersion})
 		}
-	}(packageFile)
+	}
 
-	packageBytes, err := io.ReadAll(packageFile)
+	for _, str := range strs {
+		if len(str.path) != 2 || !slices.Contains(npmDependencyTypes, str.path[0]) || !slices.Contains(bumpedNames, str.path[1]) {
+			continue
+		}
+
+		newRange, ok := updateNPMRange(str.value, oldVersion, newVersion)
+		if !ok {
+			continue
+		}
+
+		log.Debug().Msgf("Updating %s dependency on %s from %s to %s", packageFilePath, str.path[1], str.value, newRange)
+		lockEdits = append(lockEdits, npmLockFileEdit{packageDir: packageDir, path: str.path, value: newRange})
+
+		str.value = newRange
+		edits = append(edits, str)
+	}
+
+	if len(edits) == 0 {
+		return nil, nil
+	}
+
+	packageBytes, err = replaceJSONStrings(packageBytes, edits)
 	if err != nil {
-		return fmt.Errorf("error reading package.json: %w", err)
+		return nil, fmt.Errorf("error updating %s: %w", packageFilePath, err)
 	}
 
-	packageContents := string(packageBytes)
+	if err := p.store.WriteFile(packageFilePath, packageBytes); err != nil {
+		return nil, fmt.Errorf("error writing %s: %w", packageFilePath, err)
+	}
 
-	// We don't want the 'v' in the package.json version.
-	if newVersion[0] == 'v' {
-		newVersion = newVersion[1:]
+	p.addChangedFile(packageFilePath)
+	return lockEdits, nil
+}
+
+// updateNPMRange updates a dependency range if it refers to the old version, or if the new version no longer
+// satisfies it. More complicated ranges (and tags, URLs, workspace:* etc.) are left alone.
+func updateNPMRange(versionRange string, oldVersion string, newVersion string) (string, bool) {
+	matches := npmRangeRe.FindStringSubmatch(versionRange)
+	if matches == nil {
+		return "", false
 	}
-	replacementStr := fmt.Sprintf(`$1"version"$2:$3"%s"$4`, newVersion)
 
-	packageContents = packageVersionRe.ReplaceAllString(packageContents, replacementStr)
+	prefix, rangeVersion := matches[1], matches[2]
+	if rangeVersion == oldVersion {
+		return prefix + newVersion, true
+	}
 
-	if err := packageFile.Close(); err != nil {
-		return fmt.Errorf("error closing package.json: %w", err)
+	// A version without an operator has to match exactly in npm.
+	operator := strings.TrimPrefix(prefix, "workspace:")
+	constraintStr := operator + rangeVersion
+	if operator == "^" {
+		if caretConstraint, ok := caretConstraint(rangeVersion); ok {
+			constraintStr = caretConstraint
+		}
 	}
 
-	packageFile, err = os.OpenFile(p.packageFilePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	constraint, err := semver.NewConstraint(constraintStr)
 	if err != nil {
-		return fmt.Errorf("error opening package.json for writing: %w", err)
+		return "", false
 	}
-	defer func(packageFile *os.File) {
-		err := packageFile.Close()
-		if err != nil {
-			log.Error().Err(err).Msg("error closing package.json")
+
+	version, err := semver.NewVersion(newVersion)
+	if err != nil || constraint.Check(version) {
+		return "", false
+	}
+
+	return prefix + newVersion, true
+}
+
+// updateLockFile makes the lock file match the package.json changes. The root package's version is at the top
+// level and under packages[""], and workspace packages are under packages[<path relative to the lock file>].
+func (p *NPMPackager) updateLockFile(lockEdits []npmLockFileEdit) error {
+	lockBytes, err := p.store.ReadFile(p.lockFilePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", p.lockFilePath, err)
+	}
+
+	strs, err := findJSONStrings(lockBytes)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", p.lockFilePath, err)
+	}
+
+	var edits []jsonString
+	addEdit := func(value string, path ...string) {
+		if str := findJSONString(strs, path...); str != nil && str.value != value {
+			str.value = value
+			edits = append(edits, *str)
+		}
+	}
+
+	for _, lockEdit := range lockEdits {
+		lockKey, ok := p.lockFileKey(lockEdit.packageDir)
+		if !ok {
+			continue
+		}
+
+		// Older lock files only have the root package's version at the top level.
+		if lockKey == "" && slices.Equal(lockEdit.path, []string{"version"}) {
+			addEdit(lockEdit.value, "version")
 		}
-	}(packageFile)
 
-	if _, err = packageFile.WriteString(packageContents); err != nil {
-		return fmt.Errorf("error writing package.json: %w", err)
+		addEdit(lockEdit.value, slices.Concat([]string{"packages", lockKey}, lockEdit.path)...)
 	}
 
+	if len(edits) == 0 {
+		return nil
+	}
+
+	lockBytes, err = replaceJSONStrings(lockBytes, edits)
+	if err != nil {
+		return fmt.Errorf("error updating %s: %w", p.lockFilePath, err)
+	}
+
+	if err := p.store.WriteFile(p.lockFilePath, lockBytes); err != nil {
+		return fmt.Errorf("error writing %s: %w", p.lockFilePath, err)
+	}
+
+	p.addChangedFile(p.lockFilePath)
 	return nil
 }
+
+// lockFileKey returns the key of the package in the "packages" section of the lock file, which is the path of the
+// package relative to the lock file.
+func (p *NPMPackager) lockFileKey(packageDir string) (string, bool) {
+	absPackageDir, err := filepath.Abs(packageDir)
+	if err != nil {
+		return "", false
+	}
+
+	relPath, err := filepath.Rel(filepath.Dir(p.lockFilePath), absPackageDir)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		return "", false
+	}
+
+	if relPath == "." {
+		return "", true
+	}
+
+	return filepath.ToSlash(relPath), true
+}
+
+func (p *NPMPackager) addChangedFile(filePath string) {
+	if !slices.Contains(p.changedFilePaths, filePath) {
+		p.changedFilePaths = append(p.changedFilePaths, filePath)
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"errors"
+	"path"
+	"slices"
+	"testing"
+)
+
+func TestUpdateNPMRange(t *testing.T) {
+	tests := []struct {
+		versionRange string
+		newVersion   string
+		want         string
+		wantOK       bool
+	}{
+		{"1.2.3", "1.3.0", "1.3.0", true},
+		{"v1.2.3", "1.3.0", "1.3.0", true},
+		{"^1.2.3", "1.3.0", "^1.3.0", true},
+		{"workspace:~1.2.3", "1.3.0", "workspace:~1.3.0", true},
+		{"^1.0.0", "1.3.0", "", false},
+		{"^1.0.0", "2.0.0", "^2.0.0", true},
+		{"~1.2.0", "1.2.4", "", false},
+		{"~1.2.0", "1.3.0", "~1.3.0", true},
+		{"1.2.0", "1.3.0", "1.3.0", true},
+		{"^0.1.0", "0.1.5", "", false},
+		{"^0.1.0", "0.2.0", "^0.2.0", true},
+		{"workspace:*", "1.3.0", "", false},
+		{">=1.0.0 <2.0.0", "2.0.0", "", false},
+		{"latest", "2.0.0", "", false},
+		{"github:user/repo", "2.0.0", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.versionRange+" to "+tt.newVersion, func(t *testing.T) {
+			got, ok := updateNPMRange(tt.versionRange, "1.2.3", tt.newVersion)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("updateNPMRange() = %q, %t, want %q, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNPMBumpPackage(t *testing.T) {
+	projectPath := t.TempDir()
+	packageFilePath := path.Join(projectPath, "package.json")
+	writeTestFile(t, path.Join(projectPath, ".git/HEAD"), "ref: refs/heads/main\n")
+	writeTestFile(t, packageFilePath, "{\n\t\"name\": \"demo\",\n\t\"version\": \"1.2.3\",\n\t\"dependencies\": {\"demo-utils\": \"1.2.3\"}\n}\n")
+
+	packager := &NPMPackager{store: &DiskFileStore{}}
+	if err := packager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := packager.Version(); got != "v1.2.3" {
+		t.Errorf("Version() = %s, want v1.2.3", got)
+	}
+
+	if err := packager.BumpVersion("v1.3.0"); err != nil {
+		t.Fatalf("BumpVersion() error = %v", err)
+	}
+
+	// Dependencies are only updated if they're on one of the bumped packages.
+	assertFileContents(t, packageFilePath, "{\n\t\"name\": \"demo\",\n\t\"version\": \"1.3.0\",\n\t\"dependencies\": {\"demo-utils\": \"1.2.3\"}\n}\n")
+
+	if got := packager.PackageFilePaths(); !slices.Equal(got, []string{packageFilePath}) {
+		t.Errorf("PackageFilePaths() = %v, want %v", got, []string{packageFilePath})
+	}
+}
+
+func TestNPMBumpWorkspaces(t *testing.T) {
+	projectPath := t.TempDir()
+
+	files := map[string]string{
+		".git/HEAD": "ref: refs/heads/main\n",
+		"package.json": `{
+  "name": "root",
+  "version": "1.2.3",
+  "workspaces": ["packages/*", "!packages/legacy"],
+  "devDependencies": {
+    "a": "^1.2.3"
+  }
+}
+`,
+		"packages/a/package.json": `{
+  "name": "a",
+  "version": "1.2.3"
+}
+`,
+		"packages/b/package.json": `{
+  "name": "b",
+  "version": "0.4.0",
+  "dependencies": {
+    "a": "~1.2.0",
+    "left-pad": "1.2.3"
+  },
+  "peerDependencies": {
+    "root": "^1.0.0"
+  }
+}
+`,
+		"packages/legacy/package.json": `{
+  "name": "legacy",
+  "version": "1.2.3",
+  "dependencies": {
+    "a": "1.2.3"
+  }
+}
+`,
+		"package-lock.json": `{
+  "name": "root",
+  "version": "1.2.3",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {
+      "name": "root",
+      "version": "1.2.3",
+      "devDependencies": {
+        "a": "^1.2.3"
+      }
+    },
+    "node_modules/a": {
+      "resolved": "packages/a",
+      "link": true
+    },
+    "node_modules/left-pad": {
+      "version": "1.2.3"
+    },
+    "packages/a": {
+      "name": "a",
+      "version": "1.2.3"
+    },
+    "packages/b": {
+      "name": "b",
+      "version": "0.4.0",
+      "dependencies": {
+        "a": "~1.2.0",
+        "left-pad": "1.2.3"
+      }
+    }
+  }
+}
+`,
+	}
+	for filePath, contents := range files {
+		writeTestFile(t, path.Join(projectPath, filePath), contents)
+	}
+
+	packager := &NPMPackager{store: &DiskFileStore{}}
+	if err := packager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if err := packager.BumpVersion("v1.3.0"); err != nil {
+		t.Fatalf("BumpVersion() error = %v", err)
+	}
+
+	if got := packager.Version(); got != "v1.3.0" {
+		t.Errorf("Version() after bump = %s, want v1.3.0", got)
+	}
+
+	want := map[string]string{
+		"package.json": `{
+  "name": "root",
+  "version": "1.3.0",
+  "workspaces": ["packages/*", "!packages/legacy"],
+  "devDependencies": {
+    "a": "^1.3.0"
+  }
+}
+`,
+		"packages/a/package.json": `{
+  "name": "a",
+  "version": "1.3.0"
+}
+`,
+		"packages/b/package.json": `{
+  "name": "b",
+  "version": "0.4.0",
+  "dependencies": {
+    "a": "~1.3.0",
+    "left-pad": "1.2.3"
+  },
+  "peerDependencies": {
+    "root": "^1.0.0"
+  }
+}
+`,
+		"packages/legacy/package.json": files["packages/legacy/package.json"],
+		"package-lock.json": `{
+  "name": "root",
+  "version": "1.3.0",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {
+      "name": "root",
+      "version": "1.3.0",
+      "devDependencies": {
+        "a": "^1.3.0"
+      }
+    },
+    "node_modules/a": {
+      "resolved": "packages/a",
+      "link": true
+    },
+    "node_modules/left-pad": {
+      "version": "1.2.3"
+    },
+    "packages/a": {
+      "name": "a",
+      "version": "1.3.0"
+    },
+    "packages/b": {
+      "name": "b",
+      "version": "0.4.0",
+      "dependencies": {
+        "a": "~1.3.0",
+        "left-pad": "1.2.3"
+      }
+    }
+  }
+}
+`,
+	}
+	for filePath, contents := range want {
+		assertFileContents(t, path.Join(projectPath, filePath), contents)
+	}
+
+	wantPaths := []string{
+		path.Join(projectPath, "package.json"),
+		path.Join(projectPath, "packages/a/package.json"),
+		path.Join(projectPath, "packages/b/package.json"),
+		path.Join(projectPath, "package-lock.json"),
+	}
+	if got := packager.PackageFilePaths(); !slices.Equal(got, wantPaths) {
+		t.Errorf("PackageFilePaths() = %v, want %v", got, wantPaths)
+	}
+}
+
+func TestNPMPnpmWorkspaces(t *testing.T) {
+	projectPath := t.TempDir()
+
+	files := map[string]string{
+		".git/HEAD":    "ref: refs/heads/main\n",
+		"package.json": `{"name": "root", "version": "2.0.0", "private": true}`,
+		"pnpm-workspace.yaml": `packages:
+  # All the apps
+  - 'apps/*'
+  - "libs/ui"
+onlyBuiltDependencies:
+  - esbuild
+`,
+		"apps/web/package.json": `{"name": "web", "version": "2.0.0", "dependencies": {"ui": "workspace:*"}}`,
+		"libs/ui/package.json":  `{"name": "ui", "version": "2.0.0"}`,
+		"esbuild/package.json":  `{"name": "esbuild", "version": "2.0.0"}`,
+	}
+	for filePath, contents := range files {
+		writeTestFile(t, path.Join(projectPath, filePath), contents)
+	}
+
+	packager := &NPMPackager{store: &DiskFileStore{}}
+	if err := packager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	wantDirs := []string{path.Join(projectPath, "apps/web"), path.Join(projectPath, "libs/ui")}
+	if !slices.Equal(packager.workspaceDirs, wantDirs) {
+		t.Errorf("workspaceDirs = %v, want %v", packager.workspaceDirs, wantDirs)
+	}
+
+	if err := packager.BumpVersion("v2.1.0"); err != nil {
+		t.Fatalf("BumpVersion() error = %v", err)
+	}
+
+	want := map[string]string{
+		"package.json":          `{"name": "root", "version": "2.1.0", "private": true}`,
+		"apps/web/package.json": `{"name": "web", "version": "2.1.0", "dependencies": {"ui": "workspace:*"}}`,
+		"libs/ui/package.json":  `{"name": "ui", "version": "2.1.0"}`,
+		"esbuild/package.json":  files["esbuild/package.json"],
+	}
+	for filePath, contents := range want {
+		assertFileContents(t, path.Join(projectPath, filePath), contents)
+	}
+}
+
+func TestNPMParseErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		packageJSON string
+	}{
+		{"no version", `{"name": "demo"}`},
+		{"invalid version", `{"name": "demo", "version": "latest"}`},
+		{"invalid workspaces", `{"name": "demo", "version": "1.0.0", "workspaces": "packages/*"}`},
+		{"invalid JSON", `{"name": "demo", "version": "1.0.0",}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			writeTestFile(t, path.Join(projectPath, "package.json"), tt.packageJSON)
+
+			packager := &NPMPackager{store: &DiskFileStore{}}
+			if err := packager.Parse(projectPath); err == nil {
+				t.Error("Parse() succeeded, want error")
+			}
+		})
+	}
+
+	if err := (&NPMPackager{store: &DiskFileStore{}}).Parse(t.TempDir()); !errors.Is(err, ErrPackageNotFound) {
+		t.Errorf("Parse() error = %v, want ErrPackageNotFound", err)
+	}
+}
@@ -3,22 +3,26 @@ package main
 import (
 	"errors"
 	"fmt"
-	"io"
-	"io/fs"
-	"os"
 	"path"
-	"regexp"
 
 	"github.com/BurntSushi/toml"
-	"github.com/Masterminds/semver"
 	"github.com/rs/zerolog/log"
 )
 
-var tomlVersionRe = regexp.MustCompile(`(?m)^\s*version\s*=\s*"[^"]+"\s*$`)
-
+// PyprojectPackager bumps the version in pyproject.toml. Python versions follow PEP 440 rather than semver, so
+// they're converted to and from the semver form used for tags. If the version is dynamic, it's bumped in the file
+// the build backend reads it from instead, or if it comes from the git tags then only the tag is bumped.
 type PyprojectPackager struct {
+	store           FileStore
 	packageFilePath string
-	version         semver.Version
+	version         PEP440Version
+	tagVersion      string
+
+	// The table the version was found in, either "project" (PEP 621) or "tool.poetry".
+	versionTable string
+
+	dynamicVersion bool
+	versionSource  *pythonVersionSource
 }
 
 func (p *PyprojectPackager) Parse(projectPath string) error {
@@ -28,13 +32,7 @@ func (p *PyprojectPackager) Parse(projectPath string) error {
 		return ErrPackageNotFound
 	}
 
-	packageFile, err := os.Open(packageFilePath)
-	if err != nil {
-		return fmt.Errorf("error opening pyproject.toml: %w", err)
-	}
-	defer packageFile.Close()
-
-	packageBytes, err := io.ReadAll(packageFile)
+	packageBytes, err := p.store.ReadFile(packageFilePath)
 	if err != nil {
 		return fmt.Errorf("error reading pyproject.toml: %w", err)
 	}
@@ -44,22 +42,67 @@ func (p *PyprojectPackager) Parse(projectPath string) error {
 		return fmt.Errorf("error parsing pyproject.toml: %w", err)
 	}
 
+	p.packageFilePath = packageFilePath
+
+	if hasDynamicVersion(packageContents) {
+		return p.parseDynamicVersion(projectPath, packageContents)
+	}
+
 	// Try PEP-621 first as it's the modern standard, then fall back to Poetry's format.
+	p.versionTable = "project"
 	packageVersionRaw, err := tryParsePEP621(packageContents)
 	if err != nil {
+		p.versionTable = "tool.poetry"
 		packageVersionRaw, err = tryParsePoetry(packageContents)
 		if err != nil {
 			return errors.New("unable to find version in pyproject.toml")
 		}
 	}
 
-	version, err := semver.NewVersion(packageVersionRaw)
+	return p.setVersion(packageVersionRaw)
+}
+
+// parseDynamicVersion reads the version from the file that the build backend gets it from.
+func (p *PyprojectPackager) parseDynamicVersion(projectPath string, packageContents map[string]interface{}) error {
+	versionSource, err := findDynamicVersionSource(projectPath, packageContents)
 	if err != nil {
-		return errors.New("invalid semver version")
+		return fmt.Errorf("error finding dynamic version in pyproject.toml: %w", err)
+	}
+
+	p.dynamicVersion = true
+	p.versionSource = versionSource
+
+	if versionSource == nil {
+		log.Debug().Msg("Python version comes from git tags - only the tag will be bumped")
+		return nil
+	}
+
+	fileBytes, err := p.store.ReadFile(versionSource.filePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", versionSource.filePath, err)
+	}
+
+	matches := versionSource.pattern.FindSubmatch(fileBytes)
+	if matches == nil || matches[versionGroupIndex(versionSource.pattern)] == nil {
+		return fmt.Errorf("version not found in %s", versionSource.filePath)
+	}
+
+	return p.setVersion(string(matches[versionGroupIndex(versionSource.pattern)]))
+}
+
+func (p *PyprojectPackager) setVersion(versionRaw string) error {
+	version, err := ParsePEP440(versionRaw)
+	if err != nil {
+		return err
+	}
+
+	tagVersion, err := version.Semver()
+	if err != nil {
+		return err
 	}
 
-	p.packageFilePath = packageFilePath
 	p.version = *version
+	p.tagVersion = tagVersion
 
 	return nil
 }
@@ -102,51 +145,79 @@ func (p *PyprojectPackager) Name() string {
 }
 
 func (p *PyprojectPackager) Version() string {
-	return fmt.Sprintf("v%s", p.version.String())
+	return p.tagVersion
 }
 
-func (p *PyprojectPackager) PackageFilePath() string {
-	return p.packageFilePath
+func (p *PyprojectPackager) PackageFilePaths() []string {
+	if !p.dynamicVersion {
+		return []string{p.packageFilePath}
+	} else if p.versionSource != nil {
+		return []string{p.versionSource.filePath}
+	}
+
+	return nil
 }
 
 func (p *PyprojectPackager) BumpVersion(newVersion string) error {
-	packageFile, err := os.OpenFile(p.packageFilePath, os.O_RDWR, 0644)
+	if p.dynamicVersion && p.versionSource == nil {
+		return nil
+	}
+
+	version, err := p.version.Bump(newVersion)
 	if err != nil {
-		return fmt.Errorf("error opening pyproject.toml: %w", err)
+		return err
 	}
-	defer func(packageFile *os.File) {
-		if err := packageFile.Close(); err != nil && !errors.Is(err, fs.ErrClosed) {
-			log.Error().Err(err).Msg("error closing pyproject.toml")
+
+	if p.versionSource != nil {
+		if err := p.bumpVersionSource(version); err != nil {
+			return err
+		}
+	} else {
+		if err := p.bumpPackageFile(version); err != nil {
+			return err
 		}
-	}(packageFile)
+	}
 
-	packageBytes, err := io.ReadAll(packageFile)
+	p.version = *version
+	p.tagVersion = newVersion
+	return nil
+}
+
+func (p *PyprojectPackager) bumpVersionSource(version *PEP440Version) error {
+	fileBytes, err := p.store.ReadFile(p.versionSource.filePath)
 	if err != nil {
-		return fmt.Errorf("error reading pyproject.toml: %w", err)
+		return fmt.Errorf("error reading %s: %w", p.versionSource.filePath, err)
 	}
 
-	if newVersion[0] == 'v' {
-		newVersion = newVersion[1:]
+	fileBytes, found := replacePatternVersions(fileBytes, p.versionSource.pattern, version.String())
+	if !found {
+		return fmt.Errorf("version not found in %s", p.versionSource.filePath)
 	}
-	replacementBytes := []byte(fmt.Sprintf("version = \"%s\"\n", newVersion))
 
-	packageBytes = tomlVersionRe.ReplaceAll(packageBytes, replacementBytes)
-
-	if err := packageFile.Close(); err != nil {
-		return fmt.Errorf("error closing pyproject.toml: %w", err)
+	if err := p.store.WriteFile(p.versionSource.filePath, fileBytes); err != nil {
+		return fmt.Errorf("error writing %s: %w", p.versionSource.filePath, err)
 	}
 
-	packageFile, err = os.OpenFile(p.packageFilePath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	return nil
+}
+
+// bumpPackageFile updates the version in the table that it was found in, leaving everything else in the file
+// (including any other keys called version, e.g. in dependency tables) exactly as it was.
+func (p *PyprojectPackager) bumpPackageFile(version *PEP440Version) error {
+	packageBytes, err := p.store.ReadFile(p.packageFilePath)
 	if err != nil {
-		return fmt.Errorf("error opening pyproject.toml: %w", err)
+		return fmt.Errorf("error reading pyproject.toml: %w", err)
+	}
+
+	editor := newTOMLEditor(packageBytes)
+
+	// The version might also be set with a dotted key outside the table, e.g. `project.version = "1.2.3"`.
+	if !editor.setString(p.versionTable, "version", version.String()) &&
+		!editor.setString("", p.versionTable+".version", version.String()) {
+		return fmt.Errorf("version not found in [%s] in pyproject.toml", p.versionTable)
 	}
-	defer func(packageFile *os.File) {
-		if err := packageFile.Close(); err != nil {
-			log.Error().Err(err).Msg("error closing pyproject.toml")
-		}
-	}(packageFile)
 
-	if _, err := packageFile.Write(packageBytes); err != nil {
+	if err := p.store.WriteFile(p.packageFilePath, editor.Bytes()); err != nil {
 		return fmt.Errorf("error writing to pyproject.toml: %w", err)
 	}
 
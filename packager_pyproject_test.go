@@ -0,0 +1,219 @@
+package main
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestPyprojectBumpVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     string
+	}{
+		{
+			name: "PEP 621",
+			contents: `[project]
+name = "demo"
+version = "1.2.3"
+dependencies = [
+  "requests>=2.0",
+]
+
+[tool.poetry]
+version = "1.2.3"
+`,
+			want: `[project]
+name = "demo"
+version = "1.3.0"
+dependencies = [
+  "requests>=2.0",
+]
+
+[tool.poetry]
+version = "1.2.3"
+`,
+		},
+		{
+			name: "Poetry with dependency tables",
+			contents: `[tool.poetry]
+name = "demo"
+version = "1.2.3"
+
+[tool.poetry.dependencies]
+python = "^3.9"
+requests = { version = "1.2.3" }
+
+[tool.poetry.group.dev.dependencies.pytest]
+version = "1.2.3"
+`,
+			want: `[tool.poetry]
+name = "demo"
+version = "1.3.0"
+
+[tool.poetry.dependencies]
+python = "^3.9"
+requests = { version = "1.2.3" }
+
+[tool.poetry.group.dev.dependencies.pytest]
+version = "1.2.3"
+`,
+		},
+		{
+			name: "tool tables with their own version",
+			contents: `[tool.commitizen]
+version = "1.2.3"
+
+[tool.mypy]
+python_version = "3.12"
+
+[project]
+name = "demo"
+version = "1.2.3"
+`,
+			want: `[tool.commitizen]
+version = "1.2.3"
+
+[tool.mypy]
+python_version = "3.12"
+
+[project]
+name = "demo"
+version = "1.3.0"
+`,
+		},
+		{
+			name: "dotted key",
+			contents: `project.name = "demo"
+project.version = "1.2.3"
+
+[tool.commitizen]
+version = "1.2.3"
+`,
+			want: `project.name = "demo"
+project.version = "1.3.0"
+
+[tool.commitizen]
+version = "1.2.3"
+`,
+		},
+		{
+			name: "literal string with trailing comment",
+			contents: `[project]
+name = 'demo'
+version = '1.2.3'  # bumped by bumper
+`,
+			want: `[project]
+name = 'demo'
+version = '1.3.0'  # bumped by bumper
+`,
+		},
+		{
+			name: "multi-line strings",
+			contents: `[project]
+name = "demo"
+description = """
+version = "1.2.3"
+[tool.poetry]
+"""
+readme = '''
+version = '1.2.3'
+'''
+version = "1.2.3"
+`,
+			want: `[project]
+name = "demo"
+description = """
+version = "1.2.3"
+[tool.poetry]
+"""
+readme = '''
+version = '1.2.3'
+'''
+version = "1.3.0"
+`,
+		},
+		{
+			name: "nested arrays",
+			contents: `[project]
+name = "demo"
+matrix = [
+  ["py311", "linux"],
+  ["py312"]
+]
+version = "1.2.3"
+`,
+			want: `[project]
+name = "demo"
+matrix = [
+  ["py311", "linux"],
+  ["py312"]
+]
+version = "1.3.0"
+`,
+		},
+		{
+			name:     "CRLF",
+			contents: "[tool.poetry]\r\nname = \"demo\"\r\nversion = \"1.2.3\"\r\n\r\n[tool.poetry.dependencies]\r\nx = \"1.2.3\"\r\n",
+			want:     "[tool.poetry]\r\nname = \"demo\"\r\nversion = \"1.3.0\"\r\n\r\n[tool.poetry.dependencies]\r\nx = \"1.2.3\"\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			packageFilePath := path.Join(projectPath, "pyproject.toml")
+			writeTestFile(t, packageFilePath, tt.contents)
+
+			packager := &PyprojectPackager{store: &DiskFileStore{}}
+			if err := packager.Parse(projectPath); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if got := packager.Version(); got != "v1.2.3" {
+				t.Errorf("Version() = %s, want v1.2.3", got)
+			}
+
+			if err := packager.BumpVersion("v1.3.0"); err != nil {
+				t.Fatalf("BumpVersion() error = %v", err)
+			}
+
+			got, err := os.ReadFile(packageFilePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("pyproject.toml = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPyprojectPostRelease(t *testing.T) {
+	projectPath := t.TempDir()
+	packageFilePath := path.Join(projectPath, "pyproject.toml")
+	writeTestFile(t, packageFilePath, "[project]\nname = \"demo\"\nversion = \"1.2.0.post1\"\n")
+
+	packager := &PyprojectPackager{store: &DiskFileStore{}}
+	if err := packager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := packager.Version(); got != "v1.2.0+post.1" {
+		t.Errorf("Version() = %s, want v1.2.0+post.1", got)
+	}
+
+	if err := packager.BumpVersion("v1.2.0+post.2"); err != nil {
+		t.Fatalf("BumpVersion() error = %v", err)
+	}
+
+	assertFileContents(t, packageFilePath, "[project]\nname = \"demo\"\nversion = \"1.2.0.post2\"\n")
+
+	if err := packager.BumpVersion("v1.2.1"); err != nil {
+		t.Fatalf("BumpVersion() error = %v", err)
+	}
+
+	assertFileContents(t, packageFilePath, "[project]\nname = \"demo\"\nversion = \"1.2.1\"\n")
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// VersionFilesPackager bumps the version in the files listed in the version_files project config, for projects
+// which keep the version somewhere the other packagers don't know about, e.g. a VERSION file or a constant in the
+// source code.
+type VersionFilesPackager struct {
+	store        FileStore
+	versionFiles []VersionFile
+
+	// The files matched by each of the version files' globs.
+	filePaths [][]string
+	version   semver.Version
+}
+
+func (p *VersionFilesPackager) Parse(projectPath string) error {
+	if len(p.versionFiles) == 0 {
+		return ErrPackageNotFound
+	}
+
+	p.filePaths = make([][]string, len(p.versionFiles))
+	foundVersion := false
+
+	for i, versionFile := range p.versionFiles {
+		filePaths, err := filepath.Glob(filepath.Join(projectPath, versionFile.Glob))
+		if err != nil {
+			return fmt.Errorf("invalid version_files glob %s: %w", versionFile.Glob, err)
+		} else if len(filePaths) == 0 {
+			return fmt.Errorf("no files match version_files glob %s", versionFile.Glob)
+		}
+
+		for _, filePath := range filePaths {
+			versions, err := p.findVersions(filePath, versionFile)
+			if err != nil {
+				return err
+			}
+
+			for _, versionRaw := range versions {
+				version, err := semver.NewVersion(versionRaw)
+				if err != nil {
+					return fmt.Errorf("invalid semver version %s in %s", versionRaw, filePath)
+				}
+
+				if foundVersion && !version.Equal(&p.version) {
+					return fmt.Errorf(
+						"version %s in %s doesn't match version %s found in other version files",
+						versionRaw,
+						filePath,
+						p.version.String(),
+					)
+				}
+
+				p.version = *version
+				foundVersion = true
+			}
+		}
+
+		p.filePaths[i] = filePaths
+	}
+
+	return nil
+}
+
+// findVersions returns every version in the file matched by the version file pattern.
+func (p *VersionFilesPackager) findVersions(filePath string, versionFile VersionFile) ([]string, error) {
+	fileBytes, err := p.store.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", filePath, err)
+	}
+
+	versionGroup := versionGroupIndex(versionFile.Pattern)
+
+	var versions []string
+	for _, matches := range versionFile.Pattern.FindAllSubmatch(fileBytes, -1) {
+		if matches[versionGroup] != nil {
+			versions = append(versions, string(matches[versionGroup]))
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("version_files pattern %s doesn't match anything in %s", versionFile.Pattern, filePath)
+	}
+
+	return versions, nil
+}
+
+// versionGroupIndex returns the index of the group named "version", or the first group if there isn't one.
+func versionGroupIndex(pattern *regexp.Regexp) int {
+	if index := pattern.SubexpIndex("version"); index >= 0 {
+		return index
+	}
+
+	return 1
+}
+
+func (p *VersionFilesPackager) Name() string {
+	return "version files"
+}
+
+func (p *VersionFilesPackager) Version() string {
+	return fmt.Sprintf("v%s", p.version.String())
+}
+
+func (p *VersionFilesPackager) PackageFilePaths() []string {
+	var filePaths []string
+	for _, globFilePaths := range p.filePaths {
+		for _, filePath := range globFilePaths {
+			if !slices.Contains(filePaths, filePath) {
+				filePaths = append(filePaths, filePath)
+			}
+		}
+	}
+
+	return filePaths
+}
+
+func (p *VersionFilesPackager) BumpVersion(newVersion string) error {
+	newVersion = strings.TrimPrefix(newVersion, "v")
+
+	for i, versionFile := range p.versionFiles {
+		for _, filePath := range p.filePaths[i] {
+			if err := p.bumpFile(filePath, versionFile, newVersion); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// bumpFile replaces the version in every match of the pattern in the file.
+func (p *VersionFilesPackager) bumpFile(filePath string, versionFile VersionFile, newVersion string) error {
+	fileBytes, err := p.store.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", filePath, err)
+	}
+
+	fileBytes, found := replacePatternVersions(fileBytes, versionFile.Pattern, newVersion)
+	if !found {
+		return fmt.Errorf("version not found in %s", filePath)
+	}
+
+	if err := p.store.WriteFile(filePath, fileBytes); err != nil {
+		return fmt.Errorf("error writing %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
+// replacePatternVersions replaces the version in every match of the pattern, keeping the 'v' prefix if the existing
+// version had one. Returns false if no version was replaced, i.e. the pattern doesn't match anything or the
+// version group never participates in the match.
+func replacePatternVersions(fileBytes []byte, pattern *regexp.Regexp, newVersion string) ([]byte, bool) {
+	versionGroup := versionGroupIndex(pattern)
+	allMatchIndices := pattern.FindAllSubmatchIndex(fileBytes, -1)
+	found := false
+
+	// Go backwards so that the earlier offsets are still valid.
+	for i := len(allMatchIndices) - 1; i >= 0; i-- {
+		start, end := allMatchIndices[i][2*versionGroup], allMatchIndices[i][2*versionGroup+1]
+		if start < 0 {
+			// The version group is optional and didn't match.
+			continue
+		}
+
+		version := newVersion
+		if strings.HasPrefix(string(fileBytes[start:end]), "v") {
+			version = "v" + newVersion
+		}
+
+		fileBytes = slices.Concat(fileBytes[:start], []byte(version), fileBytes[end:])
+		found = true
+	}
+
+	return fileBytes, found
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestVersionFilesBumpVersion(t *testing.T) {
+	projectPath := t.TempDir()
+
+	files := map[string]string{
+		"VERSION": "1.2.3\n",
+		"cmd/app/version.go": `package main
+
+// Version is set at release time.
+const Version = "v1.2.3"
+`,
+		"docs/conf.py": `# The version is set below.
+version = "1.2.3"
+release = version
+`,
+	}
+	for filePath, contents := range files {
+		writeTestFile(t, path.Join(projectPath, filePath), contents)
+	}
+
+	packager := &VersionFilesPackager{
+		store: &DiskFileStore{},
+		versionFiles: []VersionFile{
+			{Glob: "VERSION", Pattern: regexp.MustCompile(`(?m)^(\d+\.\d+\.\d+)$`)},
+			{Glob: "cmd/*/version.go", Pattern: regexp.MustCompile(`(?m)Version = "(?P<version>[^"]+)"`)},
+			// The version group doesn't take part in matching the comment, which must be left alone.
+			{Glob: "docs/conf.py", Pattern: regexp.MustCompile(`(?m)^(?:# The )?version(?: = "(?P<version>[^"]+)")?`)},
+		},
+	}
+
+	if err := packager.Parse(projectPath); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := packager.Version(); got != "v1.2.3" {
+		t.Errorf("Version() = %s, want v1.2.3", got)
+	}
+
+	if err := packager.BumpVersion("v1.3.0"); err != nil {
+		t.Fatalf("BumpVersion() error = %v", err)
+	}
+
+	assertFileContents(t, path.Join(projectPath, "VERSION"), "1.3.0\n")
+	assertFileContents(t, path.Join(projectPath, "cmd/app/version.go"), `package main
+
+// Version is set at release time.
+const Version = "v1.3.0"
+`)
+	assertFileContents(t, path.Join(projectPath, "docs/conf.py"), `# The version is set below.
+version = "1.3.0"
+release = version
+`)
+}
+
+func TestVersionFilesParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		glob    string
+		pattern string
+		wantErr string
+	}{
+		{
+			"mismatched versions",
+			map[string]string{"a/VERSION": "1.2.3\n", "b/VERSION": "1.2.4\n"},
+			"*/VERSION",
+			`^(.+)$`,
+			"doesn't match version 1.2.3",
+		},
+		{"no match", map[string]string{"VERSION": "unreleased\n"}, "VERSION", `^(\d+\.\d+\.\d+)$`, "doesn't match anything"},
+		{
+			"version group never matches",
+			map[string]string{"VERSION": "version\n"},
+			"VERSION",
+			`^version(?: (?P<version>\S+))?$`,
+			"doesn't match anything",
+		},
+		{"invalid version", map[string]string{"VERSION": "latest\n"}, "VERSION", `^(.+)$`, "invalid semver version"},
+		{"no files", map[string]string{"other.txt": "1.2.3\n"}, "VERSION", `^(.+)$`, "no files match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			for filePath, contents := range tt.files {
+				writeTestFile(t, path.Join(projectPath, filePath), contents)
+			}
+
+			packager := &VersionFilesPackager{
+				store:        &DiskFileStore{},
+				versionFiles: []VersionFile{{Glob: tt.glob, Pattern: regexp.MustCompile("(?m)" + tt.pattern)}},
+			}
+
+			if err := packager.Parse(projectPath); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := (&VersionFilesPackager{store: &DiskFileStore{}}).Parse(t.TempDir()); !errors.Is(err, ErrPackageNotFound) {
+		t.Errorf("Parse() error = %v, want ErrPackageNotFound", err)
+	}
+}
+
+func TestVersionFilesBumpNoMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{"no match", "unreleased\n"},
+		{"version group never matches", "version\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			versionFilePath := path.Join(projectPath, "VERSION")
+			writeTestFile(t, versionFilePath, "version 1.2.3\n")
+
+			packager := &VersionFilesPackager{
+				store: &DiskFileStore{},
+				versionFiles: []VersionFile{
+					{Glob: "VERSION", Pattern: regexp.MustCompile(`(?m)^(?:version(?: (?P<version>\S+))?)$`)},
+				},
+			}
+			if err := packager.Parse(projectPath); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			// The file changing after it's been parsed mustn't result in the bump silently doing nothing.
+			writeTestFile(t, versionFilePath, tt.contents)
+
+			if err := packager.BumpVersion("v1.3.0"); err == nil {
+				t.Error("BumpVersion() succeeded, want error")
+			}
+
+			assertFileContents(t, versionFilePath, tt.contents)
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// The version scheme from PEP 440, including the alternative spellings which normalise to the canonical form.
+var pep440VersionRe = regexp.MustCompile(`(?i)^\s*v?` +
+	`(?:(?P<epoch>[0-9]+)!)?` +
+	`(?P<release>[0-9]+(?:\.[0-9]+)*)` +
+	`(?:[-_.]?(?P<pre_l>alpha|beta|preview|pre|rc|a|b|c)[-_.]?(?P<pre_n>[0-9]+)?)?` +
+	`(?:-(?P<post_n1>[0-9]+)|[-_.]?(?P<post_l>post|rev|r)[-_.]?(?P<post_n2>[0-9]+)?)?` +
+	`(?:[-_.]?(?P<dev_l>dev)[-_.]?(?P<dev_n>[0-9]+)?)?` +
+	`(?:\+(?P<local>[a-z0-9]+(?:[-_.][a-z0-9]+)*))?` +
+	`\s*$`)
+
+var pep440PrereleaseLabels = map[string]string{
+	"a":       "a",
+	"alpha":   "a",
+	"b":       "b",
+	"beta":    "b",
+	"c":       "rc",
+	"rc":      "rc",
+	"pre":     "rc",
+	"preview": "rc",
+}
+
+// Python pre-release versions use a different format to semver, e.g. 2.0.0rc1 instead of 2.0.0-rc.1.
+var pep440PrereleaseIDs = map[string]string{
+	"alpha": "a",
+	"beta":  "b",
+	"rc":    "rc",
+}
+
+// PEP440Version is a Python package version, e.g. 1!2.0.0rc1.post2.dev3+local. The pre-release label is empty for
+// versions which aren't pre-releases, and Post and Dev are nil for versions which aren't post or dev releases.
+type PEP440Version struct {
+	Epoch    int
+	Release  []int
+	PreLabel string
+	Pre      int
+	Post     *int
+	Dev      *int
+	Local    string
+}
+
+// ParsePEP440 parses a version, accepting any of the forms which PEP 440 allows and normalising them, e.g. 1.0-RC.1
+// is the same as 1.0rc1.
+func ParsePEP440(version string) (*PEP440Version, error) {
+	matches := pep440VersionRe.FindStringSubmatch(version)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid PEP 440 version %s", version)
+	}
+
+	group := func(name string) string {
+		return matches[pep440VersionRe.SubexpIndex(name)]
+	}
+
+	// The regex makes sure that all the numbers are valid, but they might still be too big.
+	var parseErr error
+	number := func(s string) int {
+		if s == "" {
+			return 0
+		}
+
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			parseErr = fmt.Errorf("invalid PEP 440 version %s: %w", version, err)
+		}
+
+		return n
+	}
+
+	v := PEP440Version{Epoch: number(group("epoch"))}
+
+	for _, part := range strings.Split(group("release"), ".") {
+		v.Release = append(v.Release, number(part))
+	}
+
+	if preLabel := group("pre_l"); preLabel != "" {
+		v.PreLabel = pep440PrereleaseLabels[strings.ToLower(preLabel)]
+		v.Pre = number(group("pre_n"))
+	}
+
+	if postNumber := group("post_n1"); postNumber != "" {
+		v.Post = Ptr(number(postNumber))
+	} else if group("post_l") != "" {
+		v.Post = Ptr(number(group("post_n2")))
+	}
+
+	if group("dev_l") != "" {
+		v.Dev = Ptr(number(group("dev_n")))
+	}
+
+	v.Local = strings.NewReplacer("-", ".", "_", ".").Replace(strings.ToLower(group("local")))
+
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	return &v, nil
+}
+
+// String returns the normalised form of the version.
+func (v *PEP440Version) String() string {
+	var sb strings.Builder
+
+	if v.Epoch != 0 {
+		sb.WriteString(fmt.Sprintf("%d!", v.Epoch))
+	}
+
+	for i, part := range v.Release {
+		if i > 0 {
+			sb.WriteString(".")
+		}
+		sb.WriteString(strconv.Itoa(part))
+	}
+
+	if v.PreLabel != "" {
+		sb.WriteString(fmt.Sprintf("%s%d", v.PreLabel, v.Pre))
+	}
+
+	if v.Post != nil {
+		sb.WriteString(fmt.Sprintf(".post%d", *v.Post))
+	}
+
+	if v.Dev != nil {
+		sb.WriteString(fmt.Sprintf(".dev%d", *v.Dev))
+	}
+
+	if v.Local != "" {
+		sb.WriteString("+" + v.Local)
+	}
+
+	return sb.String()
+}
+
+// Compare returns -1, 0 or 1 depending on whether the version is older than, the same as or newer than the other
+// version, using the ordering from PEP 440. For example, 1.0.dev1 < 1.0a1 < 1.0 < 1.0.post1, and 1.0 == 1.0.0.
+func (v *PEP440Version) Compare(other *PEP440Version) int {
+	if c := compareInt(v.Epoch, other.Epoch); c != 0 {
+		return c
+	}
+
+	// Missing release components count as zero.
+	for i := range max(len(v.Release), len(other.Release)) {
+		if c := compareInt(releasePart(v.Release, i), releasePart(other.Release, i)); c != 0 {
+			return c
+		}
+	}
+
+	if c := slices.Compare(v.preKey(), other.preKey()); c != 0 {
+		return c
+	}
+
+	if c := compareInt(optionalKey(v.Post, math.MinInt), optionalKey(other.Post, math.MinInt)); c != 0 {
+		return c
+	}
+
+	if c := compareInt(optionalKey(v.Dev, math.MaxInt), optionalKey(other.Dev, math.MaxInt)); c != 0 {
+		return c
+	}
+
+	// Local versions sort after the same version without a local label.
+	return strings.Compare(v.Local, other.Local)
+}
+
+// preKey orders the pre-release part of the version. Dev releases of a final release (e.g. 1.0.dev1) sort before
+// any pre-releases of it, and final releases sort after them.
+func (v *PEP440Version) preKey() []int {
+	switch {
+	case v.PreLabel == "" && v.Post == nil && v.Dev != nil:
+		return []int{-1, 0}
+	case v.PreLabel == "":
+		return []int{math.MaxInt, 0}
+	default:
+		return []int{slices.Index([]string{"a", "b", "rc"}, v.PreLabel), v.Pre}
+	}
+}
+
+func releasePart(release []int, i int) int {
+	if i < len(release) {
+		return release[i]
+	}
+
+	return 0
+}
+
+func optionalKey(n *int, missing int) int {
+	if n == nil {
+		return missing
+	}
+
+	return *n
+}
+
+func compareInt(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Semver returns the semver form of the version which is used for tags, e.g. 2.0.0rc1 is v2.0.0-rc.1 and the dev
+// release 1.3.0.dev2 is v1.3.0-dev.2. Post releases become build metadata, e.g. 1.2.0.post1 is v1.2.0+post.1, so
+// they're compared on the release segment: semver ignores build metadata, so the next patch version is 1.2.1. Tags
+// don't include the epoch, which is kept in the package file.
+//
+// Semver can't order every PEP 440 version in the same way, so versions which would sort in the wrong place are
+// rejected. Dev releases of pre-releases, e.g. 1.0a1.dev1, would sort after the pre-release rather than before it,
+// and dev releases of post releases, e.g. 1.0.post1.dev1, would sort before the release. Dev releases also sort
+// after alpha and beta pre-releases of the same version rather than before them, which is why a version can't go
+// between the two: the change of pre-release identifier from dev to alpha or beta is rejected when working out the
+// next version, and the other way round by Bump.
+func (v *PEP440Version) Semver() (string, error) {
+	if len(v.Release) > 3 {
+		return "", fmt.Errorf("version %s has more than three release components so can't be used as a semver tag", v)
+	}
+
+	if v.Local != "" {
+		return "", fmt.Errorf("version %s has a local version label so can't be released", v)
+	}
+
+	if v.Dev != nil && v.PreLabel != "" {
+		return "", fmt.Errorf("version %s is a dev release of a pre-release, which semver would order after it", v)
+	}
+
+	if v.Dev != nil && v.Post != nil {
+		return "", fmt.Errorf("version %s is a dev release of a post release, which semver would order before it", v)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(
+		"v%d.%d.%d",
+		releasePart(v.Release, 0),
+		releasePart(v.Release, 1),
+		releasePart(v.Release, 2),
+	))
+
+	for preid, pep440ID := range pep440PrereleaseIDs {
+		if pep440ID == v.PreLabel {
+			sb.WriteString(fmt.Sprintf("-%s.%d", preid, v.Pre))
+		}
+	}
+
+	if v.Dev != nil {
+		sb.WriteString(fmt.Sprintf("-dev.%d", *v.Dev))
+	}
+
+	if v.Post != nil {
+		sb.WriteString(fmt.Sprintf("+post.%d", *v.Post))
+	}
+
+	return sb.String(), nil
+}
+
+// PEP440FromSemver converts a semver version into the equivalent PEP 440 version, reversing Semver. Only alpha,
+// beta, rc and dev pre-releases have an equivalent, and the only build metadata supported is post.N for post
+// releases.
+func PEP440FromSemver(version string) (*PEP440Version, error) {
+	semverVersion, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %s: %w", version, err)
+	}
+
+	v := PEP440Version{
+		Release: []int{int(semverVersion.Major()), int(semverVersion.Minor()), int(semverVersion.Patch())},
+	}
+
+	if prerelease := semverVersion.Prerelease(); prerelease != "" {
+		unsupportedErr := fmt.Errorf(
+			"pre-release %s isn't supported by Python versions - use alpha, beta, rc or dev followed by a number",
+			prerelease,
+		)
+
+		preid, numberRaw, _ := strings.Cut(prerelease, ".")
+
+		number := 0
+		if numberRaw != "" {
+			if number, err = strconv.Atoi(numberRaw); err != nil {
+				return nil, unsupportedErr
+			}
+		}
+
+		if pep440ID, ok := pep440PrereleaseIDs[preid]; ok {
+			v.PreLabel = pep440ID
+			v.Pre = number
+		} else if preid == "dev" {
+			v.Dev = Ptr(number)
+		} else {
+			return nil, unsupportedErr
+		}
+	}
+
+	if metadata := semverVersion.Metadata(); metadata != "" {
+		postNumber, found := strings.CutPrefix(metadata, "post.")
+		post, err := strconv.Atoi(postNumber)
+		if !found || err != nil {
+			return nil, fmt.Errorf("build metadata %s isn't supported by Python versions - only post.N is", metadata)
+		}
+
+		v.Post = &post
+	}
+
+	return &v, nil
+}
+
+// Bump returns the version to bump to from the semver form of the new version. The epoch is kept, and so is the
+// number of release components if nothing is lost by doing so, e.g. bumping 1.2 to v1.3.0 gives 1.3.
+func (v *PEP440Version) Bump(newVersion string) (*PEP440Version, error) {
+	next, err := PEP440FromSemver(newVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	next.Epoch = v.Epoch
+	for len(next.Release) > max(len(v.Release), 1) && next.Release[len(next.Release)-1] == 0 {
+		next.Release = next.Release[:len(next.Release)-1]
+	}
+
+	if next.Compare(v) <= 0 {
+		return nil, fmt.Errorf("new version %s must be greater than the current version %s", next, v)
+	}
+
+	return next, nil
+}
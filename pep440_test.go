@@ -0,0 +1,221 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver"
+)
+
+func TestParsePEP440(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{"1.0", "1.0", false},
+		{" v1.2.3\n", "1.2.3", false},
+		{"1.0-RC.1", "1.0rc1", false},
+		{"1.0alpha2", "1.0a2", false},
+		{"1.0.beta", "1.0b0", false},
+		{"1.0c1", "1.0rc1", false},
+		{"1.0.0-preview3", "1.0.0rc3", false},
+		{"1.0-1", "1.0.post1", false},
+		{"1.0.rev", "1.0.post0", false},
+		{"1.0_r2", "1.0.post2", false},
+		{"1.0dev", "1.0.dev0", false},
+		{"1!2.0.post2.dev3+Local-Build_7", "1!2.0.post2.dev3+local.build.7", false},
+		{"", "", true},
+		{"1.0.x", "", true},
+		{"1.0+", "", true},
+		{"99999999999999999999.0", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := ParsePEP440(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePEP440() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParsePEP440() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPEP440Compare(t *testing.T) {
+	// In order, from the examples in PEP 440.
+	versions := []string{
+		"1.0.dev456",
+		"1.0a1",
+		"1.0a2.dev456",
+		"1.0a12.dev456",
+		"1.0a12",
+		"1.0b1.dev456",
+		"1.0b2",
+		"1.0b2.post345.dev456",
+		"1.0b2.post345",
+		"1.0rc1.dev456",
+		"1.0rc1",
+		"1.0",
+		"1.0+abc.5",
+		"1.0.post456.dev34",
+		"1.0.post456",
+		"1.0.15",
+		"1.1.dev1",
+		"1!0.1",
+	}
+
+	for i, a := range versions {
+		for j, b := range versions {
+			want := compareInt(i, j)
+			if got := mustParsePEP440(t, a).Compare(mustParsePEP440(t, b)); got != want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+
+	if got := mustParsePEP440(t, "1.0").Compare(mustParsePEP440(t, "1.0.0")); got != 0 {
+		t.Errorf("1.0.Compare(1.0.0) = %d, want 0", got)
+	}
+}
+
+func TestPEP440Semver(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{"1.2", "v1.2.0", false},
+		{"1!2.0.1", "v2.0.1", false},
+		{"2.0.0rc1", "v2.0.0-rc.1", false},
+		{"1.0a2", "v1.0.0-alpha.2", false},
+		{"1.0b1", "v1.0.0-beta.1", false},
+		{"1.3.0.dev2", "v1.3.0-dev.2", false},
+		{"1.2.3.4", "", true},
+		{"1.0+local", "", true},
+		{"1.2.0.post1", "v1.2.0+post.1", false},
+		{"1.0rc1.post1", "v1.0.0-rc.1+post.1", false},
+		{"1.0a1.dev1", "", true},
+		{"1.0.post1.dev1", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := mustParsePEP440(t, tt.version).Semver()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Semver() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Semver() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPEP440SemverOrdering(t *testing.T) {
+	// In PEP 440 order. Dev releases aren't mixed with alpha and beta pre-releases of the same version, as semver
+	// orders them the other way round.
+	versions := []string{"1.0.dev1", "1.0.dev2", "1.0rc1", "1.0rc2", "1.0", "1.1a1", "1.1b1", "1.1rc1", "1.1", "2.0"}
+
+	for i := 1; i < len(versions); i++ {
+		previous := semver.MustParse(mustSemver(t, versions[i-1]))
+		current := semver.MustParse(mustSemver(t, versions[i]))
+
+		if !current.GreaterThan(previous) {
+			t.Errorf("%s should sort after %s", current, previous)
+		}
+	}
+}
+
+func TestPEP440FromSemver(t *testing.T) {
+	for _, version := range []string{"1.2.3", "2.0.0rc1", "1.0.0a2", "1.0.0b3", "1.3.0.dev2", "1.2.0.post1", "0.1.0"} {
+		t.Run(version, func(t *testing.T) {
+			semverVersion := mustSemver(t, version)
+
+			got, err := PEP440FromSemver(semverVersion)
+			if err != nil {
+				t.Fatalf("PEP440FromSemver(%s) error = %v", semverVersion, err)
+			}
+
+			if got.String() != version {
+				t.Errorf("PEP440FromSemver(%s) = %s, want %s", semverVersion, got, version)
+			}
+		})
+	}
+
+	for _, version := range []string{
+		"latest",
+		"v1.0.0-preview.1",
+		"v1.0.0-rc.x",
+		"v1.0.0-alpha.1.dev.1",
+		"v1.0.0+build.1",
+		"v1.0.0+post.x",
+	} {
+		t.Run(version, func(t *testing.T) {
+			if got, err := PEP440FromSemver(version); err == nil {
+				t.Errorf("PEP440FromSemver(%s) = %s, want error", version, got)
+			}
+		})
+	}
+}
+
+func TestPEP440Bump(t *testing.T) {
+	tests := []struct {
+		version    string
+		newVersion string
+		want       string
+		wantErr    bool
+	}{
+		{"1.2", "v1.3.0", "1.3", false},
+		{"1.2", "v1.2.1", "1.2.1", false},
+		{"1", "v2.0.0", "2", false},
+		{"1!1.2.3", "v2.0.0", "1!2.0.0", false},
+		{"1.2.3", "v2.0.0-rc.1", "2.0.0rc1", false},
+		{"1.3.0.dev1", "v1.3.0-rc.1", "1.3.0rc1", false},
+		{"2.0.0rc1", "v2.0.0", "2.0.0", false},
+		{"1.0.0a1", "v1.0.0-dev.1", "", true},
+		{"1.2.3", "v1.2.3", "", true},
+		{"1.2.3", "v1.2.4+post.1", "1.2.4.post1", false},
+		{"1.2.0.post1", "v1.2.0+post.2", "1.2.0.post2", false},
+		{"1.2.0.post1", "v1.2.1", "1.2.1", false},
+		{"1.2.0.post1", "v1.2.0", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version+" to "+tt.newVersion, func(t *testing.T) {
+			got, err := mustParsePEP440(t, tt.version).Bump(tt.newVersion)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Bump() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			if err == nil && got.String() != tt.want {
+				t.Errorf("Bump() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func mustParsePEP440(t *testing.T, version string) *PEP440Version {
+	t.Helper()
+
+	v, err := ParsePEP440(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return v
+}
+
+func mustSemver(t *testing.T, version string) string {
+	t.Helper()
+
+	semverVersion, err := mustParsePEP440(t, version).Semver()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return semverVersion
+}
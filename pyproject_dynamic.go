@@ -0,0 +1,211 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Build requirements which take the version from the git tags when building.
+var pythonSCMRequirements = []string{"setuptools-scm", "hatch-vcs", "versioningit"}
+
+var pythonRequirementNameRe = regexp.MustCompile(`^[A-Za-z0-9._-]+`)
+
+// pythonVersionSource is the file that the build backend reads a dynamic version from, and the pattern for finding
+// the version in it.
+type pythonVersionSource struct {
+	filePath string
+	pattern  *regexp.Regexp
+}
+
+// pythonAttrPattern matches a string assignment to any of the names at the top level of a Python file, e.g.
+// `__version__ = "1.2.3"`. This is the same as the default pattern used by hatch.
+func pythonAttrPattern(names ...string) string {
+	quotedNames := make([]string, 0, len(names))
+	for _, name := range names {
+		quotedNames = append(quotedNames, regexp.QuoteMeta(name))
+	}
+
+	return fmt.Sprintf(
+		`(?m)^(?:%s)[ \t]*(?::[ \t]*str[ \t]*)?=[ \t]*['"]v?(?P<version>[^'"]+)['"]`,
+		strings.Join(quotedNames, "|"),
+	)
+}
+
+// hasDynamicVersion returns whether the version is set by the build backend rather than in pyproject.toml.
+func hasDynamicVersion(contents map[string]interface{}) bool {
+	if enabled, _ := lookupTOML(contents, "tool", "poetry-dynamic-versioning", "enable").(bool); enabled {
+		return true
+	}
+
+	dynamic, _ := lookupTOML(contents, "project", "dynamic").([]interface{})
+	return slices.Contains(dynamic, interface{}("version"))
+}
+
+// findDynamicVersionSource follows the hatch, pdm, setuptools or flit configuration to the file which the version is
+// read from. Returns nil if the version comes from the git tags, so there isn't a file to bump.
+func findDynamicVersionSource(projectPath string, contents map[string]interface{}) (*pythonVersionSource, error) {
+	if hatchVersion, ok := lookupTOML(contents, "tool", "hatch", "version").(map[string]interface{}); ok {
+		switch source, _ := hatchVersion["source"].(string); source {
+		case "", "regex":
+			filePath, _ := hatchVersion["path"].(string)
+			if filePath == "" {
+				return nil, errors.New("path not set in [tool.hatch.version]")
+			}
+
+			// Hatch searches for the pattern line by line, in the same way as (?m).
+			pattern := pythonAttrPattern("__version__", "VERSION")
+			if customPattern, ok := hatchVersion["pattern"].(string); ok {
+				pattern = "(?m)" + customPattern
+			}
+
+			return newPythonVersionSource(projectPath, filePath, pattern)
+		case "vcs":
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("unsupported hatch version source %s", source)
+		}
+	}
+
+	if pdmVersion, ok := lookupTOML(contents, "tool", "pdm", "version").(map[string]interface{}); ok {
+		switch source, _ := pdmVersion["source"].(string); source {
+		case "file":
+			filePath, _ := pdmVersion["path"].(string)
+			if filePath == "" {
+				return nil, errors.New("path not set in [tool.pdm.version]")
+			}
+
+			return newPythonVersionSource(projectPath, filePath, pythonAttrPattern("__version__"))
+		case "scm":
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("unsupported pdm version source %s", source)
+		}
+	}
+
+	setuptoolsVersion, ok := lookupTOML(contents, "tool", "setuptools", "dynamic", "version").(map[string]interface{})
+	if ok {
+		if attr, ok := setuptoolsVersion["attr"].(string); ok {
+			module, name, found := cutLast(attr, ".")
+			if !found {
+				return nil, fmt.Errorf("invalid setuptools version attr %s", attr)
+			}
+
+			return findPythonModuleSource(projectPath, contents, module, pythonAttrPattern(name))
+		}
+
+		// The file can be a list of files which are concatenated, but only a single file makes sense for a version.
+		filePath, _ := setuptoolsVersion["file"].(string)
+		if filePaths, ok := setuptoolsVersion["file"].([]interface{}); ok && len(filePaths) == 1 {
+			filePath, _ = filePaths[0].(string)
+		}
+
+		if filePath == "" {
+			return nil, errors.New("unsupported setuptools dynamic version - use either attr or a single file")
+		}
+
+		return newPythonVersionSource(projectPath, filePath, `^\s*v?(?P<version>\S+)`)
+	}
+
+	if enabled, _ := lookupTOML(contents, "tool", "poetry-dynamic-versioning", "enable").(bool); enabled {
+		return nil, nil
+	}
+
+	if lookupTOML(contents, "tool", "setuptools_scm") != nil {
+		return nil, nil
+	}
+
+	requirements, _ := lookupTOML(contents, "build-system", "requires").([]interface{})
+	for _, requirement := range requirements {
+		requirementStr, _ := requirement.(string)
+		name := strings.ToLower(strings.ReplaceAll(pythonRequirementNameRe.FindString(requirementStr), "_", "-"))
+		if slices.Contains(pythonSCMRequirements, name) {
+			return nil, nil
+		}
+	}
+
+	// Flit reads __version__ from the module with the same name as the project, unless it's configured otherwise.
+	buildBackend, _ := lookupTOML(contents, "build-system", "build-backend").(string)
+	if strings.HasPrefix(buildBackend, "flit_core") {
+		module, _ := lookupTOML(contents, "tool", "flit", "module", "name").(string)
+		if module == "" {
+			projectName, _ := lookupTOML(contents, "project", "name").(string)
+			module = strings.ReplaceAll(projectName, "-", "_")
+		}
+
+		return findPythonModuleSource(projectPath, contents, module, pythonAttrPattern("__version__"))
+	}
+
+	return nil, errors.New("version is dynamic but the build backend's version configuration isn't supported")
+}
+
+func newPythonVersionSource(projectPath string, filePath string, pattern string) (*pythonVersionSource, error) {
+	compiledPattern, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version pattern %s: %w", pattern, err)
+	}
+
+	versionFilePath := path.Join(projectPath, filePath)
+	if !fileExists(versionFilePath) {
+		return nil, fmt.Errorf("version file %s not found", filePath)
+	}
+
+	return &pythonVersionSource{filePath: versionFilePath, pattern: compiledPattern}, nil
+}
+
+// findPythonModuleSource finds the file for a module, e.g. pkg.about, looking in the project root and in src/ (or
+// wherever the setuptools package-dir config says).
+func findPythonModuleSource(
+	projectPath string,
+	contents map[string]interface{},
+	module string,
+	pattern string,
+) (*pythonVersionSource, error) {
+	moduleDirs := []string{"", "src"}
+	if packageDir, ok := lookupTOML(contents, "tool", "setuptools", "package-dir", "").(string); ok {
+		moduleDirs = append([]string{packageDir}, moduleDirs...)
+	}
+
+	modulePath := strings.ReplaceAll(module, ".", "/")
+	for _, moduleDir := range moduleDirs {
+		packageFilePath := path.Join(moduleDir, modulePath, "__init__.py")
+		if fileExists(path.Join(projectPath, packageFilePath)) {
+			return newPythonVersionSource(projectPath, packageFilePath, pattern)
+		}
+
+		moduleFilePath := path.Join(moduleDir, modulePath+".py")
+		if fileExists(path.Join(projectPath, moduleFilePath)) {
+			return newPythonVersionSource(projectPath, moduleFilePath, pattern)
+		}
+	}
+
+	return nil, fmt.Errorf("module %s not found", module)
+}
+
+// lookupTOML returns the value at the path of keys in the decoded TOML, or nil if there isn't one.
+func lookupTOML(contents map[string]interface{}, keys ...string) interface{} {
+	var value interface{} = contents
+	for _, key := range keys {
+		table, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		value = table[key]
+	}
+
+	return value
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s string, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+
+	return s[:i], s[i+len(sep):], true
+}
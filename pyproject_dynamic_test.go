@@ -0,0 +1,202 @@
+package main
+
+import (
+	"path"
+	"testing"
+)
+
+func TestPyprojectDynamicVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string
+		wantVersion string
+		versionFile string
+		want        string
+	}{
+		{
+			name: "hatch default pattern",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"demo\"\ndynamic = [\"version\"]\n\n" +
+					"[tool.hatch.version]\npath = \"src/demo/__about__.py\"\n",
+				"src/demo/__about__.py": "__version__ = \"1.2.3\"\n\ndef f():\n    __version__ = \"0.0.0\"\n",
+			},
+			wantVersion: "v1.2.3",
+			versionFile: "src/demo/__about__.py",
+			want:        "__version__ = \"1.3.0\"\n\ndef f():\n    __version__ = \"0.0.0\"\n",
+		},
+		{
+			name: "hatch custom pattern",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"demo\"\ndynamic = [\"version\"]\n\n" +
+					"[tool.hatch.version]\nsource = \"regex\"\npath = \"demo.py\"\npattern = \"^RELEASE = '(?P<version>[^']+)'\"\n",
+				"demo.py": "VERSION = '0.0.1'\nRELEASE = '1.2.3'\n",
+			},
+			wantVersion: "v1.2.3",
+			versionFile: "demo.py",
+			want:        "VERSION = '0.0.1'\nRELEASE = '1.3.0'\n",
+		},
+		{
+			name: "pdm file",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"demo\"\ndynamic = [\"version\"]\n\n" +
+					"[tool.pdm.version]\nsource = \"file\"\npath = \"demo/version.py\"\n",
+				"demo/version.py": "__version__ = 'v1.2.3'\n",
+			},
+			wantVersion: "v1.2.3",
+			versionFile: "demo/version.py",
+			want:        "__version__ = 'v1.3.0'\n",
+		},
+		{
+			name: "setuptools attr",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"demo\"\ndynamic = [\"version\"]\n\n" +
+					"[tool.setuptools.package-dir]\n\"\" = \"lib\"\n\n" +
+					"[tool.setuptools.dynamic]\nversion = { attr = \"demo.about.VERSION\" }\n",
+				"lib/demo/about/__init__.py": "__version__ = \"0.0.1\"\nVERSION: str = \"1.2.3rc1\"\n",
+			},
+			wantVersion: "v1.2.3-rc.1",
+			versionFile: "lib/demo/about/__init__.py",
+			want:        "__version__ = \"0.0.1\"\nVERSION: str = \"1.3.0\"\n",
+		},
+		{
+			name: "setuptools file",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"demo\"\ndynamic = [\"version\"]\n\n" +
+					"[tool.setuptools.dynamic]\nversion = { file = [\"VERSION\"] }\n",
+				"VERSION": "1.2.3\n",
+			},
+			wantVersion: "v1.2.3",
+			versionFile: "VERSION",
+			want:        "1.3.0\n",
+		},
+		{
+			name: "flit module",
+			files: map[string]string{
+				"pyproject.toml": "[build-system]\nrequires = [\"flit_core >=3.2,<4\"]\nbuild-backend = \"flit_core.buildapi\"\n\n" +
+					"[project]\nname = \"demo-lib\"\ndynamic = [\"version\", \"description\"]\n",
+				"src/demo_lib.py": "\"\"\"A demo.\"\"\"\n\n__version__ = \"1.2.3\"\n",
+			},
+			wantVersion: "v1.2.3",
+			versionFile: "src/demo_lib.py",
+			want:        "\"\"\"A demo.\"\"\"\n\n__version__ = \"1.3.0\"\n",
+		},
+		{
+			name: "hatch vcs",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"demo\"\ndynamic = [\"version\"]\n\n[tool.hatch.version]\nsource = \"vcs\"\n",
+			},
+		},
+		{
+			name: "setuptools-scm build requirement",
+			files: map[string]string{
+				"pyproject.toml": "[build-system]\nrequires = [\"setuptools>=64\", \"setuptools_scm[toml]>=8\"]\n\n" +
+					"[project]\nname = \"demo\"\ndynamic = [\"version\"]\n",
+			},
+		},
+		{
+			name: "poetry dynamic versioning",
+			files: map[string]string{
+				"pyproject.toml": "[tool.poetry]\nname = \"demo\"\nversion = \"0.0.0\"\n\n" +
+					"[tool.poetry-dynamic-versioning]\nenable = true\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			for filePath, contents := range tt.files {
+				writeTestFile(t, path.Join(projectPath, filePath), contents)
+			}
+
+			packager := &PyprojectPackager{store: &DiskFileStore{}}
+			if err := packager.Parse(projectPath); err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if got := packager.Version(); got != tt.wantVersion {
+				t.Errorf("Version() = %q, want %q", got, tt.wantVersion)
+			}
+
+			if err := packager.BumpVersion("v1.3.0"); err != nil {
+				t.Fatalf("BumpVersion() error = %v", err)
+			}
+
+			// The version comes from the tags, so there's nothing to bump.
+			if tt.versionFile == "" {
+				if got := packager.PackageFilePaths(); got != nil {
+					t.Errorf("PackageFilePaths() = %v, want nil", got)
+				}
+
+				assertFileContents(t, path.Join(projectPath, "pyproject.toml"), tt.files["pyproject.toml"])
+				return
+			}
+
+			versionFilePath := path.Join(projectPath, tt.versionFile)
+			if got := packager.PackageFilePaths(); len(got) != 1 || got[0] != versionFilePath {
+				t.Errorf("PackageFilePaths() = %v, want [%s]", got, versionFilePath)
+			}
+
+			assertFileContents(t, versionFilePath, tt.want)
+			assertFileContents(t, path.Join(projectPath, "pyproject.toml"), tt.files["pyproject.toml"])
+		})
+	}
+}
+
+func TestPyprojectDynamicVersionErrors(t *testing.T) {
+	dynamicProject := "[project]\nname = \"demo\"\ndynamic = [\"version\"]\n\n"
+
+	tests := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"hatch without path", map[string]string{"pyproject.toml": dynamicProject + "[tool.hatch.version]\n"}},
+		{
+			"unsupported hatch source",
+			map[string]string{"pyproject.toml": dynamicProject + "[tool.hatch.version]\nsource = \"code\"\npath = \"demo.py\"\n"},
+		},
+		{
+			"missing version file",
+			map[string]string{"pyproject.toml": dynamicProject + "[tool.hatch.version]\npath = \"demo.py\"\n"},
+		},
+		{
+			"version not in file",
+			map[string]string{
+				"pyproject.toml": dynamicProject + "[tool.hatch.version]\npath = \"demo.py\"\n",
+				"demo.py":        "version = \"1.2.3\"\n",
+			},
+		},
+		{
+			"attr without module",
+			map[string]string{"pyproject.toml": dynamicProject + "[tool.setuptools.dynamic]\nversion = { attr = \"VERSION\" }\n"},
+		},
+		{
+			"attr module not found",
+			map[string]string{
+				"pyproject.toml": dynamicProject + "[tool.setuptools.dynamic]\nversion = { attr = \"demo.__version__\" }\n",
+			},
+		},
+		{
+			"multiple setuptools files",
+			map[string]string{
+				"pyproject.toml": dynamicProject + "[tool.setuptools.dynamic]\nversion = { file = [\"VERSION\", \"SUFFIX\"] }\n",
+				"VERSION":        "1.2.3\n",
+			},
+		},
+		{"unknown backend", map[string]string{"pyproject.toml": dynamicProject}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := t.TempDir()
+			for filePath, contents := range tt.files {
+				writeTestFile(t, path.Join(projectPath, filePath), contents)
+			}
+
+			packager := &PyprojectPackager{store: &DiskFileStore{}}
+			if err := packager.Parse(projectPath); err == nil {
+				t.Error("Parse() succeeded, want error")
+			}
+		})
+	}
+}
@@ -14,7 +14,7 @@ var sshRemoteRe = regexp.MustCompile(`^git@([^:]+):(.+?)(?:\.git)?$`)
 
 type ReleaseCreator interface {
 	IsCorrectServer() bool
-	CreateRelease(newVersion string, releaseNotes string) (*url.URL, error)
+	CreateRelease(tagName string, newVersion string, releaseNotes string, prerelease bool) (*url.URL, error)
 	Name() string
 }
 
@@ -25,49 +25,113 @@ func getReleaseCreator(projectName string, conf *Config) (ReleaseCreator, error)
 		return nil, fmt.Errorf("error getting origin remote URL: %w", err)
 	}
 
-	serverURL := ""
-	if strings.HasPrefix(remoteURL, "git@") {
-		matches := sshRemoteRe.FindStringSubmatch(remoteURL)
-		if len(matches) != 3 {
-			return nil, fmt.Errorf("error parsing remote URL: %s", remoteURL)
-		}
+	serverURL, projectPath, err := parseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, err
+	}
 
-		serverURL = matches[1]
-	} else {
-		matches := httpsRemoteRe.FindStringSubmatch(remoteURL)
-		if len(matches) != 3 {
-			return nil, fmt.Errorf("error parsing remote URL: %s", remoteURL)
+	// GitHub goes first as github.com can be recognised without making any requests, and GitLab goes last as
+	// its check accepts anything which doesn't explicitly 404.
+	githubCreator, err := NewGitHubReleaseCreator(serverURL, projectPath, projectName)
+	if err != nil {
+		return nil, fmt.Errorf("error creating GitHub release creator: %w", err)
+	}
+
+	if githubCreator.IsCorrectServer() {
+		if conf.GithubAPIKey == "" && !conf.DryRun {
+			apiKey, err := promptForAPIKey("Please specify a GitHub API key with 'contents:write' permission")
+			if err != nil {
+				return nil, err
+			}
+
+			conf.GithubAPIKey = apiKey
+			if err := conf.Write(); err != nil {
+				return nil, fmt.Errorf("error saving configuration: %w", err)
+			}
 		}
 
-		serverURL = matches[1]
+		githubCreator.authenticate(conf.GithubAPIKey)
+		return githubCreator, nil
 	}
 
-	creator, err := NewGitLabReleaseCreator(conf, serverURL, projectName)
+	giteaCreator, err := NewGiteaReleaseCreator(conf, serverURL, projectPath, projectName)
 	if err != nil {
-		return nil, fmt.Errorf("error creating GitLab release creator: %w", err)
+		return nil, fmt.Errorf("error creating Gitea release creator: %w", err)
 	}
 
-	if creator.IsCorrectServer() {
-		if conf.GitlabAPIKey == "" {
-			prompt := promptui.Prompt{
-				Label:       "Please specify a GitLab API key with 'api' permission",
-				HideEntered: true,
+	if giteaCreator.IsCorrectServer() {
+		if conf.GiteaAPIKey == "" && !conf.DryRun {
+			apiKey, err := promptForAPIKey("Please specify a Gitea / Forgejo access token with 'write:repository' scope")
+			if err != nil {
+				return nil, err
 			}
 
-			result, err := prompt.Run()
+			conf.GiteaAPIKey = apiKey
+			if err := conf.Write(); err != nil {
+				return nil, fmt.Errorf("error saving configuration: %w", err)
+			}
+
+			giteaCreator.apiKey = apiKey
+		}
+
+		return giteaCreator, nil
+	}
+
+	gitlabCreator, err := NewGitLabReleaseCreator(conf, serverURL, projectName)
+	if err != nil {
+		return nil, fmt.Errorf("error creating GitLab release creator: %w", err)
+	}
+
+	if gitlabCreator.IsCorrectServer() {
+		if conf.GitlabAPIKey == "" && !conf.DryRun {
+			apiKey, err := promptForAPIKey("Please specify a GitLab API key with 'api' permission")
 			if err != nil {
-				return nil, fmt.Errorf("error prompting for GitLab API key: %w", err)
+				return nil, err
 			}
 
-			conf.GitlabAPIKey = result
+			conf.GitlabAPIKey = apiKey
 			if err := conf.Write(); err != nil {
 				return nil, fmt.Errorf("error saving configuration: %w", err)
 			}
+
+			gitlabCreator, err = NewGitLabReleaseCreator(conf, serverURL, projectName)
+			if err != nil {
+				return nil, fmt.Errorf("error creating GitLab release creator: %w", err)
+			}
 		}
 
-		return creator, nil
+		return gitlabCreator, nil
 	}
 
-	// Could also support GitHub here.
 	return nil, fmt.Errorf("no supported release creator found")
 }
+
+func promptForAPIKey(label string) (string, error) {
+	prompt := promptui.Prompt{
+		Label:       label,
+		HideEntered: true,
+	}
+
+	result, err := prompt.Run()
+	if err != nil {
+		return "", fmt.Errorf("error prompting for API key: %w", err)
+	}
+
+	return result, nil
+}
+
+// parseRemoteURL splits a git remote URL (either SSH or HTTPS) into the server hostname and the project path.
+func parseRemoteURL(remoteURL string) (string, string, error) {
+	var matches []string
+	if strings.HasPrefix(remoteURL, "git@") {
+		matches = sshRemoteRe.FindStringSubmatch(remoteURL)
+	} else {
+		matches = httpsRemoteRe.FindStringSubmatch(remoteURL)
+	}
+
+	if len(matches) != 3 {
+		return "", "", fmt.Errorf("error parsing remote URL: %s", remoteURL)
+	}
+
+	return matches[1], matches[2], nil
+}
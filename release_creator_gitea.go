@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// GiteaReleaseCreator creates releases on Gitea and Forgejo servers. Forgejo is a fork of Gitea and keeps the
+// Gitea API compatible, so the same creator handles both.
+type GiteaReleaseCreator struct {
+	httpClient  *http.Client
+	baseURL     *url.URL
+	apiKey      string
+	projectPath string
+	projectName string
+}
+
+type giteaVersion struct {
+	Version string `json:"version"`
+}
+
+type giteaCreateReleaseOptions struct {
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+type giteaRelease struct {
+	ID      int64  `json:"id"`
+	HTMLURL string `json:"html_url"`
+}
+
+type giteaCreatePullRequestOptions struct {
+	Head  string `json:"head"`
+	Base  string `json:"base"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type giteaMergePullRequestOptions struct {
+	Do                     string `json:"Do"`
+	MergeWhenChecksSucceed bool   `json:"merge_when_checks_succeed"`
+}
+
+type giteaPullRequest struct {
+	Number         int    `json:"number"`
+	HTMLURL        string `json:"html_url"`
+	State          string `json:"state"`
+	Merged         bool   `json:"merged"`
+	MergeCommitSHA string `json:"merge_commit_sha"`
+}
+
+// NewGiteaReleaseCreator creates a Gitea / Forgejo release creator. The server URL is normally just the hostname
+// from the origin remote, in which case HTTPS is assumed, but it can also be a full URL with a scheme.
+func NewGiteaReleaseCreator(
+	conf *Config,
+	serverURL string,
+	projectPath string,
+	projectName string,
+) (*GiteaReleaseCreator, error) {
+	if !strings.Contains(serverURL, "://") {
+		serverURL = fmt.Sprintf("https://%s", serverURL)
+	}
+
+	baseURL, err := url.Parse(strings.TrimSuffix(serverURL, "/") + "/api/v1/")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Gitea server URL: %w", err)
+	}
+
+	return &GiteaReleaseCreator{
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		baseURL:     baseURL,
+		apiKey:      conf.GiteaAPIKey,
+		projectPath: projectPath,
+		projectName: projectName,
+	}, nil
+}
+
+// IsCorrectServer returns true if the server responds to the Gitea version endpoint. The request is sent without the
+// API key, as we don't know yet that the server is Gitea.
+func (g *GiteaReleaseCreator) IsCorrectServer() bool {
+	var version giteaVersion
+	err := g.sendRequest(http.MethodGet, "version", "", nil, &version)
+
+	// Instances which require sign-in reject unauthenticated API requests, but the error still looks like Gitea's.
+	var apiErr *giteaAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.isGiteaAuthError()
+	} else if err != nil {
+		return false
+	}
+
+	log.Debug().Msgf("Found Gitea-compatible server version %s", version.Version)
+
+	// GitLab and GitHub both 404 on this endpoint, but check we got a sensible response in case something else
+	// is sitting behind the URL and answering everything.
+	return version.Version != ""
+}
+
+func (g *GiteaReleaseCreator) CreateRelease(
+	tagName string,
+	newVersion string,
+	releaseNotes string,
+	prerelease bool,
+) (*url.URL, error) {
+	opts := giteaCreateReleaseOptions{
+		TagName:    tagName,
+		Name:       fmt.Sprintf("%s %s", g.projectName, newVersion),
+		Body:       releaseNotes,
+		Prerelease: prerelease,
+	}
+
+	var release giteaRelease
+	if err := g.doRequest(
+		http.MethodPost,
+		fmt.Sprintf("repos/%s/releases", g.projectPath),
+		&opts,
+		&release,
+	); err != nil {
+		return nil, fmt.Errorf("error creating release: %w", err)
+	}
+
+	releaseURL, err := url.Parse(release.HTMLURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing release URL: %w", err)
+	}
+
+	return releaseURL, nil
+}
+
+func (g *GiteaReleaseCreator) Name() string {
+	return "Gitea"
+}
+
+// doRequest sends an authenticated JSON request to the Gitea API, relative to the API base URL, and decodes the JSON
+// response into result (if not nil).
+func (g *GiteaReleaseCreator) doRequest(method string, endpoint string, body any, result any) error {
+	return g.sendRequest(method, endpoint, g.apiKey, body, result)
+}
+
+// sendRequest is doRequest with the API key passed explicitly, so that it can be left out.
+func (g *GiteaReleaseCreator) sendRequest(method string, endpoint string, apiKey string, body any, result any) error {
+	requestURL := g.baseURL.JoinPath(endpoint)
+
+	var requestBody io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error encoding request body: %w", err)
+		}
+
+		requestBody = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequest(method, requestURL.String(), requestBody)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("token %s", apiKey))
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %w", err)
+	}
+	defer func(body io.ReadCloser) {
+		if err := body.Close(); err != nil {
+			log.Error().Err(err).Msg("error closing response body")
+		}
+	}(resp.Body)
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &giteaAPIError{method: method, url: requestURL, response: resp, body: respBytes}
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(respBytes, result); err != nil {
+			return fmt.Errorf("error decoding response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// giteaAPIError is returned for responses with an unsuccessful status code.
+type giteaAPIError struct {
+	method   string
+	url      *url.URL
+	response *http.Response
+	body     []byte
+}
+
+func (e *giteaAPIError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status %s: %s", e.method, e.url, e.response.Status, e.body)
+}
+
+// isGiteaAuthError returns whether the error is Gitea (or Forgejo) refusing an unauthenticated request. Gitea's API
+// errors link to its swagger docs, and its session cookie is named after it.
+func (e *giteaAPIError) isGiteaAuthError() bool {
+	if e.response.StatusCode != http.StatusUnauthorized && e.response.StatusCode != http.StatusForbidden {
+		return false
+	}
+
+	for _, cookie := range e.response.Cookies() {
+		if cookie.Name == "i_like_gitea" || cookie.Name == "i_like_forgejo" {
+			return true
+		}
+	}
+
+	var apiError struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(e.body, &apiError); err != nil {
+		return false
+	}
+
+	return strings.HasSuffix(apiError.URL, "/api/swagger")
+}
+
+func (g *GiteaReleaseCreator) CreateMergeRequest(
+	sourceBranch string,
+	targetBranch string,
+	title string,
+	description string,
+) (*MergeRequest, error) {
+	opts := giteaCreatePullRequestOptions{
+		Head:  sourceBranch,
+		Base:  targetBranch,
+		Title: title,
+		Body:  description,
+	}
+
+	var pullRequest giteaPullRequest
+	if err := g.doRequest(
+		http.MethodPost,
+		fmt.Sprintf("repos/%s/pulls", g.projectPath),
+		&opts,
+		&pullRequest,
+	); err != nil {
+		return nil, fmt.Errorf("error creating pull request: %w", err)
+	}
+
+	pullRequestURL, err := url.Parse(pullRequest.HTMLURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing pull request URL: %w", err)
+	}
+
+	return &MergeRequest{ID: pullRequest.Number, URL: pullRequestURL}, nil
+}
+
+func (g *GiteaReleaseCreator) EnableAutoMerge(id int) error {
+	opts := giteaMergePullRequestOptions{
+		Do:                     "merge",
+		MergeWhenChecksSucceed: true,
+	}
+
+	if err := g.doRequest(
+		http.MethodPost,
+		fmt.Sprintf("repos/%s/pulls/%d/merge", g.projectPath, id),
+		&opts,
+		nil,
+	); err != nil {
+		return fmt.Errorf("error scheduling pull request merge: %w", err)
+	}
+
+	return nil
+}
+
+func (g *GiteaReleaseCreator) GetMergeCommit(id int) (string, error) {
+	var pullRequest giteaPullRequest
+	if err := g.doRequest(
+		http.MethodGet,
+		fmt.Sprintf("repos/%s/pulls/%d", g.projectPath, id),
+		nil,
+		&pullRequest,
+	); err != nil {
+		return "", fmt.Errorf("error getting pull request: %w", err)
+	}
+
+	if pullRequest.Merged {
+		if pullRequest.MergeCommitSHA == "" {
+			return "", fmt.Errorf("pull request #%d was merged but has no merge commit", id)
+		}
+
+		return pullRequest.MergeCommitSHA, nil
+	}
+
+	if pullRequest.State == "closed" {
+		return "", fmt.Errorf("pull request #%d was closed without being merged", id)
+	}
+
+	return "", nil
+}
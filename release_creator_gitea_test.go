@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestGiteaReleaseCreator(t *testing.T, handler http.HandlerFunc) *GiteaReleaseCreator {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	creator, err := NewGiteaReleaseCreator(&Config{GiteaAPIKey: "secret"}, server.URL, "owner/repo", "Demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return creator
+}
+
+func TestGiteaIsCorrectServer(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		cookie string
+		body   string
+		want   bool
+	}{
+		{"gitea", http.StatusOK, "", `{"version": "1.22.3"}`, true},
+		{"forgejo", http.StatusOK, "", `{"version": "9.0.0+gitea-1.22.0"}`, true},
+		{
+			"private gitea",
+			http.StatusUnauthorized,
+			"",
+			`{"message": "token is required", "url": "https://gitea.example.com/api/swagger"}`,
+			true,
+		},
+		{"private forgejo", http.StatusForbidden, "i_like_forgejo=abc", `{"message": "Forbidden"}`, true},
+		{"unauthorised other server", http.StatusUnauthorized, "", `{"message": "401 Unauthorized"}`, false},
+		{"other server with swagger", http.StatusNotFound, "", `{"url": "https://example.com/api/swagger"}`, false},
+		{"github or gitlab", http.StatusNotFound, "", `{"message": "Not Found"}`, false},
+		{"answers everything", http.StatusOK, "", `{"status": "ok"}`, false},
+		{"not json", http.StatusOK, "", `<html></html>`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creator := newTestGiteaReleaseCreator(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/version" {
+					http.NotFound(w, r)
+					return
+				}
+
+				// The server might not be Gitea, so it mustn't be sent the API key.
+				if auth := r.Header.Get("Authorization"); auth != "" {
+					t.Errorf("Authorization = %q, want none", auth)
+				}
+
+				if tt.cookie != "" {
+					w.Header().Set("Set-Cookie", tt.cookie)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+
+			if got := creator.IsCorrectServer(); got != tt.want {
+				t.Errorf("IsCorrectServer() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGiteaCreateRelease(t *testing.T) {
+	for _, prerelease := range []bool{false, true} {
+		var gotOpts giteaCreateReleaseOptions
+
+		creator := newTestGiteaReleaseCreator(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != "/api/v1/repos/owner/repo/releases" {
+				t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			}
+
+			if auth := r.Header.Get("Authorization"); auth != "token secret" {
+				t.Errorf("Authorization = %q, want %q", auth, "token secret")
+			}
+
+			if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", contentType)
+			}
+
+			if err := json.NewDecoder(r.Body).Decode(&gotOpts); err != nil {
+				t.Errorf("error decoding request body: %v", err)
+			}
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 1, "html_url": "https://gitea.example.com/owner/repo/releases/tag/api/v1.2.0"}`))
+		})
+
+		releaseURL, err := creator.CreateRelease("api/v1.2.0", "v1.2.0", "## v1.2.0\n\n- Fix <thing>\n", prerelease)
+		if err != nil {
+			t.Fatalf("CreateRelease() error = %v", err)
+		}
+
+		if releaseURL.String() != "https://gitea.example.com/owner/repo/releases/tag/api/v1.2.0" {
+			t.Errorf("release URL = %s", releaseURL)
+		}
+
+		wantOpts := giteaCreateReleaseOptions{
+			TagName:    "api/v1.2.0",
+			Name:       "Demo v1.2.0",
+			Body:       "## v1.2.0\n\n- Fix <thing>\n",
+			Prerelease: prerelease,
+		}
+		if gotOpts != wantOpts {
+			t.Errorf("request body = %+v, want %+v", gotOpts, wantOpts)
+		}
+	}
+}
+
+func TestGiteaDoRequestErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr string
+	}{
+		{
+			name: "error status",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"message": "token is required"}`))
+			},
+			wantErr: "unexpected status 401 Unauthorized: {\"message\": \"token is required\"}",
+		},
+		{
+			name: "invalid json",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(`not json`))
+			},
+			wantErr: "error decoding response",
+		},
+		{
+			name: "truncated body",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Length", "100")
+				_, _ = io.WriteString(w, `{"version":`)
+			},
+			wantErr: "error reading response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creator := newTestGiteaReleaseCreator(t, tt.handler)
+
+			var version giteaVersion
+			err := creator.doRequest(http.MethodGet, "version", nil, &version)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("doRequest() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("unreachable server", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		creator, err := NewGiteaReleaseCreator(&Config{}, server.URL, "owner/repo", "Demo")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := creator.doRequest(http.MethodGet, "version", nil, nil); err == nil ||
+			!strings.Contains(err.Error(), "error sending request") {
+			t.Errorf("doRequest() error = %v, want error sending request", err)
+		}
+	})
+}
+
+func TestGiteaNoAuthHeaderWithoutAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("unexpected Authorization header %q", auth)
+		}
+
+		_, _ = w.Write([]byte(`{"version": "1.22.3"}`))
+	}))
+	defer server.Close()
+
+	creator, err := NewGiteaReleaseCreator(&Config{}, server.URL, "owner/repo", "Demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !creator.IsCorrectServer() {
+		t.Error("expected server to be recognised as Gitea")
+	}
+}
+
+func TestGiteaGetMergeCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{"open", `{"number": 7, "state": "open", "merged": false}`, "", false},
+		{"merged", `{"number": 7, "state": "closed", "merged": true, "merge_commit_sha": "abc123"}`, "abc123", false},
+		{"merged without commit", `{"number": 7, "state": "closed", "merged": true}`, "", true},
+		{"closed", `{"number": 7, "state": "closed", "merged": false}`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creator := newTestGiteaReleaseCreator(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/repos/owner/repo/pulls/7" {
+					http.NotFound(w, r)
+					return
+				}
+
+				_, _ = w.Write([]byte(tt.body))
+			})
+
+			got, err := creator.GetMergeCommit(7)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetMergeCommit() error = %v, want error %t", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("GetMergeCommit() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
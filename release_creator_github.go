@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v66/github"
+)
+
+const githubPublicHost = "github.com"
+
+type GitHubReleaseCreator struct {
+	githubClient *github.Client
+	serverURL    string
+	owner        string
+	repo         string
+	projectName  string
+}
+
+// NewGitHubReleaseCreator creates a release creator for either github.com or a GitHub Enterprise Server instance,
+// depending on the server URL. The client starts off unauthenticated so that the API key isn't sent to the server
+// before we know it's GitHub - call authenticate once IsCorrectServer has confirmed it.
+func NewGitHubReleaseCreator(
+	serverURL string,
+	projectPath string,
+	projectName string,
+) (*GitHubReleaseCreator, error) {
+	owner, repo, found := strings.Cut(projectPath, "/")
+	if !found || owner == "" || repo == "" {
+		return nil, fmt.Errorf("unexpected GitHub project path: %s", projectPath)
+	}
+
+	githubClient := github.NewClient(&http.Client{Timeout: 30 * time.Second})
+
+	if serverURL != githubPublicHost {
+		// GitHub Enterprise Server hosts the API under /api/v3/ rather than on a separate api. subdomain.
+		var err error
+		githubClient, err = githubClient.WithEnterpriseURLs(
+			fmt.Sprintf("https://%s/api/v3/", serverURL),
+			fmt.Sprintf("https://%s/api/uploads/", serverURL),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error creating GitHub Enterprise client: %w", err)
+		}
+	}
+
+	return &GitHubReleaseCreator{
+		githubClient: githubClient,
+		serverURL:    serverURL,
+		owner:        owner,
+		repo:         repo,
+		projectName:  projectName,
+	}, nil
+}
+
+// IsCorrectServer returns true if the server is github.com or a GitHub Enterprise Server instance.
+func (g *GitHubReleaseCreator) IsCorrectServer() bool {
+	if g.serverURL == githubPublicHost {
+		return true
+	}
+
+	// The meta endpoint doesn't require authentication and only exists on GitHub servers.
+	_, _, err := g.githubClient.Meta.Get(context.Background())
+	if err == nil {
+		return true
+	}
+
+	// In private mode, GitHub Enterprise Server requires authentication even for the meta endpoint, but the
+	// response headers still show that it's GitHub. A 401 is fine here - we might not have the API key yet.
+	var errorResponse *github.ErrorResponse
+	if errors.As(err, &errorResponse) && errorResponse.Response != nil {
+		response := errorResponse.Response
+		return response.StatusCode == http.StatusUnauthorized &&
+			(response.Header.Get("X-GitHub-Request-Id") != "" || response.Header.Get("X-GitHub-Enterprise-Version") != "")
+	}
+
+	return false
+}
+
+// authenticate adds the API key to the client's requests. This should only be called once the server is known to
+// be GitHub.
+func (g *GitHubReleaseCreator) authenticate(apiKey string) {
+	if apiKey != "" {
+		g.githubClient = g.githubClient.WithAuthToken(apiKey)
+	}
+}
+
+func (g *GitHubReleaseCreator) CreateRelease(
+	tagName string,
+	newVersion string,
+	releaseNotes string,
+	prerelease bool,
+) (*url.URL, error) {
+	opts := github.RepositoryRelease{
+		Name:       github.String(fmt.Sprintf("%s %s", g.projectName, newVersion)),
+		TagName:    &tagName,
+		Body:       &releaseNotes,
+		Prerelease: &prerelease,
+	}
+
+	release, _, err := g.githubClient.Repositories.CreateRelease(context.Background(), g.owner, g.repo, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("error creating release: %w", err)
+	}
+
+	releaseURL, err := url.Parse(release.GetHTMLURL())
+	if err != nil {
+		return nil, fmt.Errorf("error parsing release URL: %w", err)
+	}
+
+	return releaseURL, nil
+}
+
+func (g *GitHubReleaseCreator) Name() string {
+	return "GitHub"
+}
+
+func (g *GitHubReleaseCreator) CreateMergeRequest(
+	sourceBranch string,
+	targetBranch string,
+	title string,
+	description string,
+) (*MergeRequest, error) {
+	opts := github.NewPullRequest{
+		Title: &title,
+		Head:  &sourceBranch,
+		Base:  &targetBranch,
+		Body:  &description,
+	}
+
+	pullRequest, _, err := g.githubClient.PullRequests.Create(context.Background(), g.owner, g.repo, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("error creating pull request: %w", err)
+	}
+
+	pullRequestURL, err := url.Parse(pullRequest.GetHTMLURL())
+	if err != nil {
+		return nil, fmt.Errorf("error parsing pull request URL: %w", err)
+	}
+
+	return &MergeRequest{ID: pullRequest.GetNumber(), URL: pullRequestURL}, nil
+}
+
+// EnableAutoMerge turns on auto-merge for the pull request. This is only available via the GraphQL API, and
+// auto-merge needs to be allowed in the repository settings.
+func (g *GitHubReleaseCreator) EnableAutoMerge(id int) error {
+	pullRequest, _, err := g.githubClient.PullRequests.Get(context.Background(), g.owner, g.repo, id)
+	if err != nil {
+		return fmt.Errorf("error getting pull request: %w", err)
+	}
+
+	graphqlURL := "https://api.github.com/graphql"
+	if g.serverURL != githubPublicHost {
+		graphqlURL = fmt.Sprintf("https://%s/api/graphql", g.serverURL)
+	}
+
+	query := map[string]any{
+		"query": `mutation($id: ID!) { enablePullRequestAutoMerge(input: {pullRequestId: $id}) { clientMutationId } }`,
+		"variables": map[string]any{
+			"id": pullRequest.GetNodeID(),
+		},
+	}
+
+	req, err := g.githubClient.NewRequest(http.MethodPost, graphqlURL, query)
+	if err != nil {
+		return fmt.Errorf("error creating GraphQL request: %w", err)
+	}
+
+	var result struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := g.githubClient.Do(context.Background(), req, &result); err != nil {
+		return fmt.Errorf("error enabling auto-merge: %w", err)
+	}
+
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("error enabling auto-merge: %s", result.Errors[0].Message)
+	}
+
+	return nil
+}
+
+// GetMergeCommit returns the merge commit of the pull request. For squash and rebase merges, GitHub sets this to
+// the squashed commit or the last of the rebased commits respectively.
+func (g *GitHubReleaseCreator) GetMergeCommit(id int) (string, error) {
+	pullRequest, _, err := g.githubClient.PullRequests.Get(context.Background(), g.owner, g.repo, id)
+	if err != nil {
+		return "", fmt.Errorf("error getting pull request: %w", err)
+	}
+
+	if pullRequest.GetMerged() {
+		if pullRequest.GetMergeCommitSHA() == "" {
+			return "", fmt.Errorf("pull request #%d was merged but has no merge commit", id)
+		}
+
+		return pullRequest.GetMergeCommitSHA(), nil
+	}
+
+	if pullRequest.GetState() == "closed" {
+		return "", fmt.Errorf("pull request #%d was closed without being merged", id)
+	}
+
+	return "", nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestGitHubReleaseCreator creates a GitHub Enterprise release creator which talks to the test server.
+func newTestGitHubReleaseCreator(t *testing.T, server *httptest.Server) *GitHubReleaseCreator {
+	t.Helper()
+
+	creator, err := NewGitHubReleaseCreator("github.example.com", "owner/repo", "Demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	creator.githubClient, err = creator.githubClient.WithEnterpriseURLs(
+		server.URL+"/api/v3/",
+		server.URL+"/api/uploads/",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return creator
+}
+
+func TestGitHubIsCorrectServer(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		headers map[string]string
+		want    bool
+	}{
+		{"public meta endpoint", http.StatusOK, map[string]string{"X-GitHub-Request-Id": "1234"}, true},
+		{"private mode", http.StatusUnauthorized, map[string]string{"X-GitHub-Request-Id": "1234"}, true},
+		{"private mode enterprise header", http.StatusUnauthorized, map[string]string{"X-GitHub-Enterprise-Version": "3.14"}, true},
+		{"unauthorised other server", http.StatusUnauthorized, nil, false},
+		{"not found", http.StatusNotFound, map[string]string{"X-GitHub-Request-Id": "1234"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v3/meta" {
+					http.NotFound(w, r)
+					return
+				}
+
+				for key, value := range tt.headers {
+					w.Header().Set(key, value)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"message": "response"}`))
+			}))
+			defer server.Close()
+
+			if got := newTestGitHubReleaseCreator(t, server).IsCorrectServer(); got != tt.want {
+				t.Errorf("IsCorrectServer() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubPublicServerIsRecognised(t *testing.T) {
+	creator, err := NewGitHubReleaseCreator(githubPublicHost, "owner/repo", "Demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !creator.IsCorrectServer() {
+		t.Error("expected github.com to be recognised without making any requests")
+	}
+
+	if creator.githubClient.Client().Timeout == 0 {
+		t.Error("expected the GitHub client to have a timeout")
+	}
+}
+
+func TestGitHubAPIKeyOnlySentAfterDetection(t *testing.T) {
+	authHeaders := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders[r.URL.Path] = r.Header.Get("Authorization")
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/meta":
+			_, _ = w.Write([]byte(`{}`))
+		case "/api/v3/repos/owner/repo/releases":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"html_url": "https://github.example.com/owner/repo/releases/v1.0.0"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	creator := newTestGitHubReleaseCreator(t, server)
+	if !creator.IsCorrectServer() {
+		t.Fatal("expected server to be recognised as GitHub")
+	}
+
+	if auth := authHeaders["/api/v3/meta"]; auth != "" {
+		t.Errorf("meta request Authorization = %q, want none", auth)
+	}
+
+	creator.authenticate("secret")
+	if _, err := creator.CreateRelease("v1.0.0", "v1.0.0", "notes", false); err != nil {
+		t.Fatalf("CreateRelease() error = %v", err)
+	}
+
+	if auth := authHeaders["/api/v3/repos/owner/repo/releases"]; auth != "Bearer secret" {
+		t.Errorf("release request Authorization = %q, want Bearer secret", auth)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"strings"
 
 	"github.com/xanzy/go-gitlab"
 )
@@ -36,7 +35,14 @@ func (g *GitLabReleaseCreator) IsCorrectServer() bool {
 	return true
 }
 
-func (g *GitLabReleaseCreator) CreateRelease(newVersion string, releaseNotes string) (*url.URL, error) {
+// CreateRelease creates the release. GitLab doesn't have a separate flag for pre-releases - they're shown based on
+// the tag name - so the pre-release flag is ignored.
+func (g *GitLabReleaseCreator) CreateRelease(
+	tagName string,
+	newVersion string,
+	releaseNotes string,
+	_ bool,
+) (*url.URL, error) {
 	projectID, err := g.getProjectID()
 	if err != nil {
 		return nil, fmt.Errorf("error getting project ID: %w", err)
@@ -44,7 +50,7 @@ func (g *GitLabReleaseCreator) CreateRelease(newVersion string, releaseNotes str
 
 	opts := gitlab.CreateReleaseOptions{
 		Name:        gitlab.Ptr(fmt.Sprintf("%s %s", g.projectName, newVersion)),
-		TagName:     &newVersion,
+		TagName:     &tagName,
 		Description: &releaseNotes,
 	}
 
@@ -72,21 +78,9 @@ func (g *GitLabReleaseCreator) getProjectID() (int, error) {
 		return 0, fmt.Errorf("error getting origin remote URL: %w", err)
 	}
 
-	projectPath := ""
-	if strings.HasPrefix(remoteURL, "git@") {
-		matches := sshRemoteRe.FindStringSubmatch(remoteURL)
-		if len(matches) != 3 {
-			return 0, fmt.Errorf("error parsing remote URL: %s", remoteURL)
-		}
-
-		projectPath = matches[2]
-	} else {
-		matches := httpsRemoteRe.FindStringSubmatch(remoteURL)
-		if len(matches) != 3 {
-			return 0, fmt.Errorf("error parsing remote URL: %s", remoteURL)
-		}
-
-		projectPath = matches[2]
+	_, projectPath, err := parseRemoteURL(remoteURL)
+	if err != nil {
+		return 0, err
 	}
 
 	// You can actually just use the project path for all API calls, but getting the proper project ID
@@ -98,3 +92,84 @@ func (g *GitLabReleaseCreator) getProjectID() (int, error) {
 
 	return project.ID, nil
 }
+
+func (g *GitLabReleaseCreator) CreateMergeRequest(
+	sourceBranch string,
+	targetBranch string,
+	title string,
+	description string,
+) (*MergeRequest, error) {
+	projectID, err := g.getProjectID()
+	if err != nil {
+		return nil, fmt.Errorf("error getting project ID: %w", err)
+	}
+
+	opts := gitlab.CreateMergeRequestOptions{
+		Title:              &title,
+		Description:        &description,
+		SourceBranch:       &sourceBranch,
+		TargetBranch:       &targetBranch,
+		RemoveSourceBranch: gitlab.Ptr(true),
+	}
+
+	mergeRequest, _, err := g.gitlabClient.MergeRequests.CreateMergeRequest(projectID, &opts)
+	if err != nil {
+		return nil, fmt.Errorf("error creating merge request: %w", err)
+	}
+
+	mergeRequestURL, err := url.Parse(mergeRequest.WebURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing merge request URL: %w", err)
+	}
+
+	return &MergeRequest{ID: mergeRequest.IID, URL: mergeRequestURL}, nil
+}
+
+func (g *GitLabReleaseCreator) EnableAutoMerge(id int) error {
+	projectID, err := g.getProjectID()
+	if err != nil {
+		return fmt.Errorf("error getting project ID: %w", err)
+	}
+
+	opts := gitlab.AcceptMergeRequestOptions{
+		MergeWhenPipelineSucceeds: gitlab.Ptr(true),
+	}
+
+	if _, _, err := g.gitlabClient.MergeRequests.AcceptMergeRequest(projectID, id, &opts); err != nil {
+		return fmt.Errorf("error setting merge request to merge when pipeline succeeds: %w", err)
+	}
+
+	return nil
+}
+
+// GetMergeCommit returns the merge commit of the merge request or, if it was merged without a merge commit, the
+// squash commit or the head of the merge request for fast-forward merges.
+func (g *GitLabReleaseCreator) GetMergeCommit(id int) (string, error) {
+	projectID, err := g.getProjectID()
+	if err != nil {
+		return "", fmt.Errorf("error getting project ID: %w", err)
+	}
+
+	mergeRequest, _, err := g.gitlabClient.MergeRequests.GetMergeRequest(projectID, id, nil)
+	if err != nil {
+		return "", fmt.Errorf("error getting merge request: %w", err)
+	}
+
+	switch mergeRequest.State {
+	case "merged":
+		if mergeRequest.MergeCommitSHA != "" {
+			return mergeRequest.MergeCommitSHA, nil
+		} else if mergeRequest.SquashCommitSHA != "" {
+			return mergeRequest.SquashCommitSHA, nil
+		} else if mergeRequest.SHA != "" {
+			// Fast-forward merges move the target branch to the head of the merge request.
+			return mergeRequest.SHA, nil
+		}
+
+		return "", fmt.Errorf("merge request !%d was merged but its commit is unknown", id)
+	case "closed":
+		return "", fmt.Errorf("merge request !%d was closed without being merged", id)
+	default:
+		return "", nil
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var tomlTableHeaderRe = regexp.MustCompile(`^\s*\[\[?\s*([^\[\]]+?)\s*\]\]?\s*(?:#.*)?$`)
+
+// tomlEditor edits values in a TOML file line by line, so that comments, ordering and formatting are left exactly
+// as they were. It only understands as much TOML as is needed to know which table each line belongs to.
+type tomlEditor struct {
+	lines []string
+}
+
+func newTOMLEditor(contents []byte) *tomlEditor {
+	return &tomlEditor{lines: strings.Split(string(contents), "\n")}
+}
+
+func (e *tomlEditor) Bytes() []byte {
+	return []byte(strings.Join(e.lines, "\n"))
+}
+
+// forEachLine calls fn with the index of each line and the name of the table it's in, e.g. "tool.poetry" or
+// "target.cfg(unix).dependencies". Lines which start inside multi-line strings or arrays are skipped, as they can't
+// be keys or table headers, and so are the table headers themselves. Top-level keys are in the table "".
+func (e *tomlEditor) forEachLine(fn func(table string, i int)) {
+	table := ""
+	var scanner tomlScanner
+
+	for i, line := range e.lines {
+		if scanner.multilineDelim != "" || scanner.arrayDepth > 0 {
+			scanner.scanLine(line)
+			continue
+		}
+
+		if matches := tomlTableHeaderRe.FindStringSubmatch(line); matches != nil {
+			table = normaliseTOMLKey(matches[1])
+			continue
+		}
+
+		scanner.scanLine(line)
+		fn(table, i)
+	}
+}
+
+// tomlScanner keeps track of the multi-line strings and arrays which are still open at the end of each line.
+type tomlScanner struct {
+	multilineDelim string
+	arrayDepth     int
+}
+
+func (s *tomlScanner) scanLine(line string) {
+	for i := 0; i < len(line); {
+		if s.multilineDelim != "" {
+			switch {
+			case s.multilineDelim == `"""` && line[i] == '\\':
+				i += 2
+			case strings.HasPrefix(line[i:], s.multilineDelim):
+				// The string can end with up to two quotes, which come before the delimiter, e.g. """say "hi"""".
+				i += len(s.multilineDelim)
+				for i < len(line) && line[i] == s.multilineDelim[0] {
+					i++
+				}
+				s.multilineDelim = ""
+			default:
+				i++
+			}
+
+			continue
+		}
+
+		switch {
+		case line[i] == '#':
+			return
+		case strings.HasPrefix(line[i:], `"""`) || strings.HasPrefix(line[i:], `'''`):
+			s.multilineDelim = line[i : i+3]
+			i += 3
+		case line[i] == '"':
+			i++
+			for i < len(line) && line[i] != '"' {
+				if line[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case line[i] == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end == -1 {
+				return
+			}
+			i += end + 2
+		case line[i] == '[':
+			s.arrayDepth++
+			i++
+		case line[i] == ']':
+			s.arrayDepth = max(s.arrayDepth-1, 0)
+			i++
+		default:
+			i++
+		}
+	}
+}
+
+// getString returns the string value of the key in the table, if there is one.
+func (e *tomlEditor) getString(table string, key string) (string, bool) {
+	keyRe := tomlStringKeyRe(key)
+
+	value, found := "", false
+	e.forEachLine(func(lineTable string, i int) {
+		if found || lineTable != table {
+			return
+		}
+
+		if matches := keyRe.FindStringSubmatch(e.lines[i]); matches != nil {
+			value, found = matches[3], true
+		}
+	})
+
+	return value, found
+}
+
+// setString replaces the string value of the key in the table, keeping the quote style and anything else on the
+// line. Returns false if the key wasn't found.
+func (e *tomlEditor) setString(table string, key string, value string) bool {
+	keyRe := tomlStringKeyRe(key)
+
+	found := false
+	e.forEachLine(func(lineTable string, i int) {
+		if found || lineTable != table {
+			return
+		}
+
+		if keyRe.MatchString(e.lines[i]) {
+			e.lines[i] = keyRe.ReplaceAllString(e.lines[i], "${1}${2}"+escapeReplacement(value)+"${4}")
+			found = true
+		}
+	})
+
+	return found
+}
+
+// tomlStringKeyRe matches a "key = value" line where the value is a basic or literal string. The groups are the
+// prefix up to the opening quote, the opening quote, the value and the closing quote onwards.
+func tomlStringKeyRe(key string) *regexp.Regexp {
+	return regexp.MustCompile(`^(\s*` + regexp.QuoteMeta(key) + `\s*=\s*)(["'])([^"']*)(["'].*)$`)
+}
+
+// normaliseTOMLKey strips whitespace and quotes from each part of a dotted key, e.g. `target."cfg(unix)".dependencies`
+// becomes `target.cfg(unix).dependencies`.
+func normaliseTOMLKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// escapeReplacement escapes dollar signs so that the string can be used literally in a regexp replacement.
+func escapeReplacement(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestTOMLEditorTables(t *testing.T) {
+	contents := `name = "top"
+
+[package]
+keywords = [
+  ["not", "a table"],
+  "]",
+  '[also.not.a.table]',
+]
+description = """
+[not.a.table]
+name = "in a string"
+"""
+license = '''
+[[not.an.array.of.tables]]'''
+include = [ # a comment with a ]
+  ["x"]
+]
+name = "demo"
+
+[ target . "cfg(unix)" . dependencies ] # comment
+name = "unix"
+
+[[bin]]
+name = "first"
+[[bin]]
+name = "second"
+`
+
+	var got []string
+	editor := newTOMLEditor([]byte(contents))
+	editor.forEachLine(func(table string, i int) {
+		if matches := tomlStringKeyRe("name").FindStringSubmatch(editor.lines[i]); matches != nil {
+			got = append(got, table+"="+matches[3])
+		}
+	})
+
+	want := []string{"=top", "package=demo", "target.cfg(unix).dependencies=unix", "bin=first", "bin=second"}
+	if len(got) != len(want) {
+		t.Fatalf("names = %v, want %v", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("names[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestTOMLEditorSetString(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		table    string
+		key      string
+		value    string
+		want     string
+		wantOK   bool
+	}{
+		{
+			name:     "basic string",
+			contents: "[package]\nversion = \"1.0.0\"\n",
+			table:    "package",
+			key:      "version",
+			value:    "2.0.0",
+			want:     "[package]\nversion = \"2.0.0\"\n",
+			wantOK:   true,
+		},
+		{
+			name:     "literal string and comment",
+			contents: "[package]\n  version='1.0.0'   # the version\n",
+			table:    "package",
+			key:      "version",
+			value:    "2.0.0",
+			want:     "[package]\n  version='2.0.0'   # the version\n",
+			wantOK:   true,
+		},
+		{
+			name:     "only the first match in the table",
+			contents: "[package]\nversion = \"1.0.0\"\n[dependencies.x]\nversion = \"1.0.0\"\n",
+			table:    "package",
+			key:      "version",
+			value:    "2.0.0",
+			want:     "[package]\nversion = \"2.0.0\"\n[dependencies.x]\nversion = \"1.0.0\"\n",
+			wantOK:   true,
+		},
+		{
+			name:     "CRLF",
+			contents: "[package]\r\nversion = \"1.0.0\"\r\n",
+			table:    "package",
+			key:      "version",
+			value:    "2.0.0",
+			want:     "[package]\r\nversion = \"2.0.0\"\r\n",
+			wantOK:   true,
+		},
+		{
+			name:     "dotted key",
+			contents: "package.version = \"1.0.0\"\n",
+			table:    "",
+			key:      "package.version",
+			value:    "2.0.0",
+			want:     "package.version = \"2.0.0\"\n",
+			wantOK:   true,
+		},
+		{
+			name:     "dollar signs are literal",
+			contents: "[package]\nversion = \"1.0.0\"\n",
+			table:    "package",
+			key:      "version",
+			value:    "$1",
+			want:     "[package]\nversion = \"$1\"\n",
+			wantOK:   true,
+		},
+		{
+			name:     "not found",
+			contents: "[package]\nname = \"demo\"\n",
+			table:    "package",
+			key:      "version",
+			value:    "2.0.0",
+			want:     "[package]\nname = \"demo\"\n",
+			wantOK:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			editor := newTOMLEditor([]byte(tt.contents))
+			if ok := editor.setString(tt.table, tt.key, tt.value); ok != tt.wantOK {
+				t.Errorf("setString() = %t, want %t", ok, tt.wantOK)
+			}
+
+			if got := string(editor.Bytes()); got != tt.want {
+				t.Errorf("contents = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
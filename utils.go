@@ -1,5 +1,54 @@
 package main
 
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
 func Ptr[T any](t T) *T {
 	return &t
 }
+
+// sameVersion returns whether the versions are the same, ignoring build metadata, e.g. a Python post release tagged
+// as v1.2.0+post.1 is the same version as v1.2.0. Versions which aren't semver versions have to match exactly.
+func sameVersion(a string, b string) bool {
+	aVersion, aErr := semver.NewVersion(a)
+	bVersion, bErr := semver.NewVersion(b)
+	if aErr != nil || bErr != nil {
+		return a == b
+	}
+
+	return aVersion.Equal(bVersion)
+}
+
+// caretConstraint converts a Cargo or npm caret requirement into a range. These allow any change which doesn't
+// modify the left-most non-zero part of the version, e.g. ^0.2.3 is >=0.2.3, <0.3.0, which is stricter than
+// semver's caret constraints for 0.x versions. Returns false for wildcard requirements like 1.*.
+func caretConstraint(version string) (string, bool) {
+	release, _, _ := strings.Cut(version, "-")
+
+	var parts []int
+	for _, partStr := range strings.Split(release, ".") {
+		part, err := strconv.Atoi(partStr)
+		if err != nil || len(parts) == 3 {
+			return "", false
+		}
+
+		parts = append(parts, part)
+	}
+
+	i := slices.IndexFunc(parts, func(part int) bool { return part != 0 })
+	if i == -1 {
+		i = len(parts) - 1
+	}
+
+	upper := make([]int, 3)
+	copy(upper, parts[:i])
+	upper[i] = parts[i] + 1
+
+	return fmt.Sprintf(">= %s, < %d.%d.%d", version, upper[0], upper[1], upper[2]), true
+}
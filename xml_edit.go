@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// xmlTextSpan is the position of the text content of an element in an XML file, excluding surrounding whitespace.
+type xmlTextSpan struct {
+	start int
+	end   int
+	text  string
+}
+
+// findXMLElementText finds the text content of every element at the given path from the root element, e.g.
+// ["project", "version"], so that it can be replaced without re-encoding (and so reformatting) the whole file.
+// Namespace prefixes are ignored, as are self-closing elements as there's nowhere to put the text.
+func findXMLElementText(contents []byte, elementPath ...string) ([]xmlTextSpan, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(contents))
+
+	var spans []xmlTextSpan
+	var stack []string
+	textStart := -1
+
+	for {
+		tokenStart := int(decoder.InputOffset())
+
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("error parsing XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			if slices.Equal(stack, elementPath) && !bytes.HasSuffix(contents[:decoder.InputOffset()], []byte("/>")) {
+				textStart = int(decoder.InputOffset())
+			}
+		case xml.EndElement:
+			if textStart >= 0 && slices.Equal(stack, elementPath) {
+				spans = append(spans, trimXMLTextSpan(contents, textStart, tokenStart))
+				textStart = -1
+			}
+
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	return spans, nil
+}
+
+func trimXMLTextSpan(contents []byte, start int, end int) xmlTextSpan {
+	for start < end && isXMLSpace(contents[start]) {
+		start++
+	}
+
+	for end > start && isXMLSpace(contents[end-1]) {
+		end--
+	}
+
+	return xmlTextSpan{start: start, end: end, text: string(contents[start:end])}
+}
+
+func isXMLSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+}
+
+// replaceXMLText replaces the text of each of the spans with the value.
+func replaceXMLText(contents []byte, spans []xmlTextSpan, value string) []byte {
+	var escapedValue bytes.Buffer
+	_ = xml.EscapeText(&escapedValue, []byte(value))
+
+	result := bytes.Clone(contents)
+
+	// Go backwards so that the earlier offsets are still valid.
+	for i := len(spans) - 1; i >= 0; i-- {
+		span := spans[i]
+		result = slices.Concat(result[:span.start], escapedValue.Bytes(), result[span.end:])
+	}
+
+	return result
+}
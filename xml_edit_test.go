@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFindXMLElementText(t *testing.T) {
+	contents := `<?xml version="1.0" encoding="UTF-8"?>
+<!-- <project><version>0.0.0</version></project> -->
+<p:project xmlns:p="http://maven.apache.org/POM/4.0.0">
+  <parent>
+    <version>3.3.0</version>
+  </parent>
+  <version>
+    1.2.3
+  </version>
+  <description><![CDATA[<version>0.0.1</version>]]></description>
+  <dependencies>
+    <dependency>
+      <version>4.5.6</version>
+    </dependency>
+  </dependencies>
+  <version/>
+  <version>1.2.3</version>
+</p:project>
+`
+
+	spans, err := findXMLElementText([]byte(contents), "project", "version")
+	if err != nil {
+		t.Fatalf("findXMLElementText() error = %v", err)
+	}
+
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2: %+v", len(spans), spans)
+	}
+
+	for _, span := range spans {
+		if span.text != "1.2.3" || contents[span.start:span.end] != "1.2.3" {
+			t.Errorf("span = %+v, text at span = %q, want 1.2.3", span, contents[span.start:span.end])
+		}
+	}
+
+	if _, err := findXMLElementText([]byte("<project><version>1.0</project>"), "project", "version"); err == nil {
+		t.Error("expected error for invalid XML")
+	}
+}
+
+func TestReplaceXMLText(t *testing.T) {
+	contents := "<project>\r\n  <version> 1.2.3 </version>\r\n  <name>A &amp; B</name>\r\n  <version>1.2.3</version>\r\n</project>"
+
+	spans, err := findXMLElementText([]byte(contents), "project", "version")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(replaceXMLText([]byte(contents), spans, "2.0.0-<&>"))
+	want := "<project>\r\n  <version> 2.0.0-&lt;&amp;&gt; </version>\r\n  <name>A &amp; B</name>\r\n" +
+		"  <version>2.0.0-&lt;&amp;&gt;</version>\r\n</project>"
+	if got != want {
+		t.Errorf("replaceXMLText() = %q, want %q", got, want)
+	}
+}